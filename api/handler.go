@@ -51,6 +51,16 @@ func (s *APIServer) Run() {
 			Handler(handler)
 	}
 
+	for _, route := range adminRoutes {
+		var handler http.Handler
+		handler = s.adminHandler(route.HandlerFunc)
+		router.
+			Methods(route.Method).
+			Path(route.Pattern).
+			Name(route.Name).
+			Handler(handler)
+	}
+
 	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		s.WriteJSON(w, http.StatusOK, ApiResponse{
 			Success: false,
@@ -80,6 +90,16 @@ func (s *APIServer) Run() {
 }
 
 func (s *APIServer) customHandler(f apiFunc) http.HandlerFunc {
+	return s.authHandler(s.cfg.Auth, f)
+}
+
+// adminHandler guards routes that only staff tooling may call. Admin routes
+// are disabled until an adminAuth key is configured.
+func (s *APIServer) adminHandler(f apiFunc) http.HandlerFunc {
+	return s.authHandler(s.cfg.AdminAuth, f)
+}
+
+func (s *APIServer) authHandler(token string, f apiFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tokenString := r.Header.Get("Authorization")
 		if tokenString == "" {
@@ -90,7 +110,7 @@ func (s *APIServer) customHandler(f apiFunc) http.HandlerFunc {
 			return
 		}
 
-		if tokenString != s.cfg.Auth {
+		if token == "" || tokenString != token {
 			s.WriteJSON(w, http.StatusOK, ApiResponse{
 				Success: false,
 				Error:   "Invalid token",
@@ -247,8 +267,42 @@ func Auctions(w http.ResponseWriter, r *http.Request, s *APIServer) error {
 				Data:    "Auction Inserted",
 			})
 
+		case "LIST_BULK":
+			results, err := s.store.ListAuctionBulk(Auction)
+			if err != nil && results == nil {
+				return err
+			}
+
+			if err != nil {
+				return s.WriteJSON(w, http.StatusOK, ApiResponse{
+					Error: err.Error(),
+					Data:  results,
+				})
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    results,
+			})
+		case "UNLIST_BULK":
+			results, err := s.store.UnlistAuctionBulk(Auction)
+			if err != nil && results == nil {
+				return err
+			}
+
+			if err != nil {
+				return s.WriteJSON(w, http.StatusOK, ApiResponse{
+					Error: err.Error(),
+					Data:  results,
+				})
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    results,
+			})
 		case "READ":
-			auctions, err := s.store.GetAuctions()
+			auctions, err := s.store.GetAuctions(Auction)
 			if err != nil {
 				return err
 			}
@@ -267,13 +321,14 @@ func Auctions(w http.ResponseWriter, r *http.Request, s *APIServer) error {
 				Data:    "Auction Deleted",
 			})
 		case "PURCHASE":
-			if err := s.store.PurchaseAuction(Auction); err != nil {
+			purchased, err := s.store.PurchaseAuction(Auction)
+			if err != nil {
 				return err
 			}
 
 			return s.WriteJSON(w, http.StatusOK, ApiResponse{
 				Success: true,
-				Data:    "Auction Purchased",
+				Data:    purchased,
 			})
 		case "AUCTION_GET_CLAIMS":
 			claims, err := s.store.GetAuctionClaims(Auction)
@@ -287,13 +342,14 @@ func Auctions(w http.ResponseWriter, r *http.Request, s *APIServer) error {
 				Data:    claims,
 			})
 		case "AUCTION_CLAIM":
-			if err := s.store.AuctionClaim(Auction); err != nil {
+			claimed, err := s.store.AuctionClaim(Auction)
+			if err != nil {
 				return err
 			}
 
 			return s.WriteJSON(w, http.StatusOK, ApiResponse{
 				Success: true,
-				Data:    "Auction Claimed",
+				Data:    claimed,
 			})
 		case "AUCTION_GET_LISTINGS":
 			listing, err := s.store.GetAuctionListing(Auction)
@@ -306,6 +362,16 @@ func Auctions(w http.ResponseWriter, r *http.Request, s *APIServer) error {
 				Success: true,
 				Data:    listing,
 			})
+		case "AUCTION_GET_EXPIRED":
+			expired, err := s.store.AuctionExpireList(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    expired,
+			})
 		case "AUCTION_UNLIST":
 			if err := s.store.AuctionUnlist(Auction); err != nil {
 				return err
@@ -315,6 +381,232 @@ func Auctions(w http.ResponseWriter, r *http.Request, s *APIServer) error {
 				Success: true,
 				Data:    "Auction Unlisted",
 			})
+		case "AUCTION_EDIT":
+			edited, err := s.store.EditAuction(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    edited,
+			})
+		case "WALLET_BALANCE":
+			balances, err := s.store.GetWalletBalances(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    balances,
+			})
+		case "WALLET_LEDGER":
+			ledger, err := s.store.GetWalletLedger(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    ledger,
+			})
+		case "WALLET_DEPOSIT":
+			balance, err := s.store.DepositWallet(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    balance,
+			})
+		case "WALLET_WITHDRAW":
+			balance, err := s.store.WithdrawWallet(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    balance,
+			})
+		case "AUCTION_EVENTS":
+			events, err := s.store.GetAuctionEvents(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    events,
+			})
+		case "RESERVE":
+			reserved, err := s.store.ReserveAuction(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    reserved,
+			})
+		case "CONFIRM":
+			purchased, err := s.store.ConfirmAuction(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    purchased,
+			})
+		case "RELEASE":
+			if err := s.store.ReleaseAuction(Auction); err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    "Reservation Released",
+			})
+		case "WATCH":
+			if err := s.store.WatchAuction(Auction); err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    "Auction Watched",
+			})
+		case "UNWATCH":
+			if err := s.store.UnwatchAuction(Auction); err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    "Auction Unwatched",
+			})
+		case "WATCHLIST":
+			watchlist, err := s.store.GetWatchlist(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    watchlist,
+			})
+		case "ALERT_CREATE":
+			alert, err := s.store.CreateAuctionAlert(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    alert,
+			})
+		case "ALERT_DELETE":
+			if err := s.store.DeleteAuctionAlert(Auction); err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    "Alert Deleted",
+			})
+		case "ALERTS":
+			alerts, err := s.store.GetAuctionAlerts(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    alerts,
+			})
+		case "OFFER":
+			offer, err := s.store.MakeOffer(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    offer,
+			})
+		case "OFFER_ACCEPT":
+			listing, err := s.store.AcceptOffer(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    listing,
+			})
+		case "OFFER_REJECT":
+			offer, err := s.store.RejectOffer(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    offer,
+			})
+		case "OFFER_COUNTER":
+			offer, err := s.store.CounterOffer(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    offer,
+			})
+		case "OFFER_CANCEL":
+			offer, err := s.store.CancelOffer(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    offer,
+			})
+		case "OFFERS":
+			offers, err := s.store.GetOffers(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    offers,
+			})
+		case "MARKET":
+			stats, err := s.store.GetMarketStats(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    stats,
+			})
+		case "BID":
+			bid, err := s.store.PlaceBid(Auction)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    bid,
+			})
 		}
 	}
 
@@ -441,6 +733,74 @@ func GetLeaderboards(w http.ResponseWriter, r *http.Request, s *APIServer) error
 	}
 }
 
+func Trades(w http.ResponseWriter, r *http.Request, s *APIServer) error {
+	if r.Method == "POST" {
+		Trade := new(models.Trade)
+		if err := json.NewDecoder(r.Body).Decode(Trade); err != nil {
+			return err
+		}
+
+		if Trade.Payload == "" {
+			return fmt.Errorf("Invalid Payload")
+		}
+
+		switch Trade.Payload {
+		case "TRADE_PROPOSE":
+			trade, err := s.store.ProposeTrade(Trade)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    trade,
+			})
+		case "TRADE_ACCEPT":
+			trade, err := s.store.AcceptTrade(Trade)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    trade,
+			})
+		case "TRADE_DECLINE":
+			trade, err := s.store.DeclineTrade(Trade)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    trade,
+			})
+		case "TRADE_CANCEL":
+			trade, err := s.store.CancelTrade(Trade)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    trade,
+			})
+		case "TRADE_HISTORY":
+			trades, err := s.store.GetTradeHistory(Trade)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    trades,
+			})
+		}
+	}
+
+	return fmt.Errorf("Invalid Method")
+}
+
 func SeasonLB(w http.ResponseWriter, r *http.Request, s *APIServer) error {
 	if r.Method == "POST" {
 		SeasonLB := new(models.SeasonLBAccount)
@@ -564,3 +924,353 @@ func GhostHunt(w http.ResponseWriter, r *http.Request, s *APIServer) error {
 
 	return fmt.Errorf("Invalid Method")
 }
+
+func AdminMailbox(w http.ResponseWriter, r *http.Request, s *APIServer) error {
+	if r.Method == "POST" {
+		Mailbox := new(models.MailboxOutboxRequest)
+		if err := json.NewDecoder(r.Body).Decode(Mailbox); err != nil {
+			return err
+		}
+
+		if Mailbox.Payload == "" {
+			return fmt.Errorf("Invalid Payload")
+		}
+
+		switch Mailbox.Payload {
+		case "LIST":
+			outbox, err := s.store.GetMailboxOutbox(Mailbox)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    outbox,
+			})
+		case "REPLAY":
+			replayed, err := s.store.ReplayMailboxOutbox(Mailbox)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    replayed,
+			})
+		}
+	}
+
+	return fmt.Errorf("Invalid Method")
+}
+
+func AdminPlayerTier(w http.ResponseWriter, r *http.Request, s *APIServer) error {
+	if r.Method == "POST" {
+		Tier := new(models.PlayerTier)
+		if err := json.NewDecoder(r.Body).Decode(Tier); err != nil {
+			return err
+		}
+
+		if Tier.Payload == "" {
+			return fmt.Errorf("Invalid Payload")
+		}
+
+		switch Tier.Payload {
+		case "GET_TIER":
+			tier, err := s.store.GetPlayerTier(Tier)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    tier,
+			})
+		case "SET_TIER":
+			tier, err := s.store.SetPlayerTier(Tier)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    tier,
+			})
+		}
+	}
+
+	return fmt.Errorf("Invalid Method")
+}
+
+func AdminFees(w http.ResponseWriter, r *http.Request, s *APIServer) error {
+	if r.Method == "POST" {
+		Report := new(models.AuctionFeeReport)
+		if err := json.NewDecoder(r.Body).Decode(Report); err != nil {
+			return err
+		}
+
+		if Report.Payload == "" {
+			return fmt.Errorf("Invalid Payload")
+		}
+
+		switch Report.Payload {
+		case "REPORT":
+			report, err := s.store.GetFeeReport(Report)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    report,
+			})
+		}
+	}
+
+	return fmt.Errorf("Invalid Method")
+}
+
+func AdminEconomy(w http.ResponseWriter, r *http.Request, s *APIServer) error {
+	if r.Method == "POST" {
+		Report := new(models.EconomyReportRequest)
+		if err := json.NewDecoder(r.Body).Decode(Report); err != nil {
+			return err
+		}
+
+		if Report.Payload == "" {
+			return fmt.Errorf("Invalid Payload")
+		}
+
+		switch Report.Payload {
+		case "REPORT":
+			report, err := s.store.GetEconomyReport(Report)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    report,
+			})
+		}
+	}
+
+	return fmt.Errorf("Invalid Method")
+}
+
+func AdminRegistry(w http.ResponseWriter, r *http.Request, s *APIServer) error {
+	if r.Method == "POST" {
+		Registry := new(models.RegistryRequest)
+		if err := json.NewDecoder(r.Body).Decode(Registry); err != nil {
+			return err
+		}
+
+		if Registry.Payload == "" {
+			return fmt.Errorf("Invalid Payload")
+		}
+
+		switch Registry.Payload {
+		case "LIST":
+			registry, err := s.store.GetRegistry()
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    registry,
+			})
+		case "SET_ITEM_TYPE":
+			entry, err := s.store.SetItemType(Registry)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    entry,
+			})
+		case "SET_PRICE_TYPE":
+			entry, err := s.store.SetPriceType(Registry)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    entry,
+			})
+		case "REMOVE_ITEM_TYPE":
+			if err := s.store.RemoveItemType(Registry); err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    "Item Type Removed",
+			})
+		case "REMOVE_PRICE_TYPE":
+			if err := s.store.RemovePriceType(Registry); err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    "Price Type Removed",
+			})
+		}
+	}
+
+	return fmt.Errorf("Invalid Method")
+}
+
+func AdminFlags(w http.ResponseWriter, r *http.Request, s *APIServer) error {
+	if r.Method == "POST" {
+		Flag := new(models.FlagRequest)
+		if err := json.NewDecoder(r.Body).Decode(Flag); err != nil {
+			return err
+		}
+
+		if Flag.Payload == "" {
+			return fmt.Errorf("Invalid Payload")
+		}
+
+		switch Flag.Payload {
+		case "LIST":
+			flags, err := s.store.GetAuctionFlags(Flag)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    flags,
+			})
+		case "REVIEW":
+			flag, err := s.store.ReviewAuctionFlag(Flag)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    flag,
+			})
+		case "PROVENANCE":
+			chain, err := s.store.GetProvenance(Flag)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    chain,
+			})
+		}
+	}
+
+	return fmt.Errorf("Invalid Method")
+}
+
+func AdminModeration(w http.ResponseWriter, r *http.Request, s *APIServer) error {
+	if r.Method == "POST" {
+		Moderation := new(models.ModerationRequest)
+		if err := json.NewDecoder(r.Body).Decode(Moderation); err != nil {
+			return err
+		}
+
+		if Moderation.Payload == "" {
+			return fmt.Errorf("Invalid Payload")
+		}
+
+		switch Moderation.Payload {
+		case "BAN":
+			ban, err := s.store.BanPlayer(Moderation)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    ban,
+			})
+		case "UNBAN":
+			ban, err := s.store.UnbanPlayer(Moderation)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    ban,
+			})
+		case "BANS":
+			bans, err := s.store.GetBans(Moderation)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    bans,
+			})
+		case "HIDE":
+			if err := s.store.HideAuction(Moderation); err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    "Auction Hidden",
+			})
+		case "UNHIDE":
+			if err := s.store.UnhideAuction(Moderation); err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    "Auction Unhidden",
+			})
+		case "REMOVE":
+			if err := s.store.ModerateRemoveAuction(Moderation); err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    "Auction Removed",
+			})
+		case "DELETE":
+			if err := s.store.ModerateDeleteAuction(Moderation); err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    "Auction Deleted",
+			})
+		case "RESTORE":
+			restored, err := s.store.RestoreAuction(Moderation)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    restored,
+			})
+		case "ACTIONS":
+			actions, err := s.store.GetModerationActions(Moderation)
+			if err != nil {
+				return err
+			}
+
+			return s.WriteJSON(w, http.StatusOK, ApiResponse{
+				Success: true,
+				Data:    actions,
+			})
+		}
+	}
+
+	return fmt.Errorf("Invalid Method")
+}
@@ -17,6 +17,7 @@ var routes = Routes{
 	Route{"LeaderboardLookup", "POST", "/lb-lookup", LeaderboardLookup},
 
 	Route{"Auction", "POST", "/auction", Auctions},
+	Route{"Trade", "POST", "/trade", Trades},
 
 	Route{"SeasonLB", "POST", "/season-lb", SeasonLB},
 	Route{"HalloweenLB", "POST", "/halloween-lb", HalloweenLB},
@@ -24,3 +25,14 @@ var routes = Routes{
 
 	Route{"PetsExistance", "POST", "/pets-exist", PetsExistance},
 }
+
+// adminRoutes require the adminAuth key instead of the game server key
+var adminRoutes = Routes{
+	Route{"AdminMailbox", "POST", "/admin/mailbox", AdminMailbox},
+	Route{"AdminPlayerTier", "POST", "/admin/player-tier", AdminPlayerTier},
+	Route{"AdminFees", "POST", "/admin/fees", AdminFees},
+	Route{"AdminEconomy", "POST", "/admin/economy", AdminEconomy},
+	Route{"AdminRegistry", "POST", "/admin/registry", AdminRegistry},
+	Route{"AdminFlags", "POST", "/admin/flags", AdminFlags},
+	Route{"AdminModeration", "POST", "/admin/moderation", AdminModeration},
+}
@@ -15,6 +15,83 @@ type AuctionAccount struct {
 	PriceType  string          `json:"priceType"`
 	Price      int64           `json:"startPrice"`
 	ListedDate time.Time       `json:"listedDate,omitempty"`
+
+	BidAmount         int64  `json:"bidAmount,omitempty"`
+	HighestBid        int64  `json:"highestBid,omitempty"`
+	HighestBidderID   int64  `json:"highestBidderId,omitempty"`
+	HighestBidderName string `json:"highestBidderName,omitempty"`
+
+	BuyerID       int64      `json:"buyerId,omitempty"`
+	BuyerName     string     `json:"buyerName,omitempty"`
+	PurchasedDate *time.Time `json:"purchasedDate,omitempty"`
+	SoldPrice     int64      `json:"soldPrice,omitempty"`
+	Escrowed      int64      `json:"escrowed,omitempty"`
+	Tax           int64      `json:"tax,omitempty"`
+	NetProceeds   int64      `json:"netProceeds,omitempty"`
+	Duration      int64      `json:"duration,omitempty"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`
+	ExpiredReason string     `json:"expiredReason,omitempty"`
+	Reserved      bool       `json:"reserved,omitempty"`
+	ReservedUntil *time.Time `json:"reservedUntil,omitempty"`
+	Hidden        bool       `json:"hidden,omitempty"`
+	Seconds       int64      `json:"seconds,omitempty"`
+	Tier          string     `json:"tier,omitempty"`
+	ClaimType     string     `json:"claimType,omitempty"`
+
+	Mode         string `json:"mode,omitempty"`
+	FloorPrice   int64  `json:"floorPrice,omitempty"`
+	PriceStep    int64  `json:"priceStep,omitempty"`
+	StepSeconds  int64  `json:"stepSeconds,omitempty"`
+	ReservePrice int64  `json:"reservePrice,omitempty"`
+	CurrentPrice int64  `json:"currentPrice,omitempty"`
+	ReserveMet   *bool  `json:"reserveMet,omitempty"`
+
+	PriceHistory []*AuctionPriceChange `json:"priceHistory,omitempty"`
+
+	Amount    int64  `json:"amount,omitempty"`
+	Reference string `json:"reference,omitempty"`
+	OfferID   int64  `json:"offerId,omitempty"`
+
+	Items []*AuctionAccount `json:"items,omitempty"`
+
+	MinPrice int64  `json:"minPrice,omitempty"`
+	MaxPrice int64  `json:"maxPrice,omitempty"`
+	Sort     string `json:"sort,omitempty"`
+	Cursor   string `json:"cursor,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+}
+
+type AuctionPage struct {
+	Listings   []*AuctionAccount `json:"listings"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+	Facets     AuctionFacets     `json:"facets"`
+}
+
+type AuctionFacets struct {
+	ItemTypes  map[string]int64 `json:"itemTypes"`
+	PriceTypes map[string]int64 `json:"priceTypes"`
+}
+
+type AuctionClaims struct {
+	Items    []*AuctionAccount `json:"items"`
+	Proceeds []*AuctionAccount `json:"proceeds"`
+}
+
+type AuctionBid struct {
+	ID         int64     `json:"id"`
+	AuctionID  int64     `json:"auctionId"`
+	RobloxID   int64     `json:"robloxId"`
+	RobloxName string    `json:"robloxName"`
+	Amount     int64     `json:"amount"`
+	PriceType  string    `json:"priceType"`
+	Status     string    `json:"status"`
+	Placed     time.Time `json:"placed"`
+
+	// Outbid is the bid a BID replaced. RefundedTo says where a refunded bid
+	// went: WALLET for escrowed bids, MAILBOX for bids placed before bids
+	// were escrowed.
+	Outbid     *AuctionBid `json:"outbid,omitempty"`
+	RefundedTo string      `json:"refundedTo,omitempty"`
 }
 
 type MailboxExpire struct {
@@ -39,3 +116,64 @@ func NewItem(ID int64, Name string, ItemType string, ItemData json.RawMessage, P
 		Price:     Price,
 	}
 }
+
+type AuctionEvent struct {
+	ID        int64           `json:"id"`
+	AuctionID int64           `json:"auctionId"`
+	Event     string          `json:"event"`
+	SellerID  int64           `json:"sellerId"`
+	ActorID   int64           `json:"actorId"`
+	ActorName string          `json:"actorName"`
+	ItemType  string          `json:"itemType"`
+	ItemData  json.RawMessage `json:"itemData"`
+	PriceType string          `json:"priceType"`
+	Price     int64           `json:"price"`
+	Detail    string          `json:"detail,omitempty"`
+	Created   time.Time       `json:"created"`
+}
+
+// AuctionPriceChange is one AUCTION_EDIT of a listing's price.
+type AuctionPriceChange struct {
+	ID           int64     `json:"id"`
+	AuctionID    int64     `json:"auctionId"`
+	OldPrice     int64     `json:"oldPrice"`
+	NewPrice     int64     `json:"newPrice"`
+	OldPriceType string    `json:"oldPriceType"`
+	NewPriceType string    `json:"newPriceType"`
+	Created      time.Time `json:"created"`
+}
+
+type AuctionAlert struct {
+	ID         int64           `json:"id"`
+	RobloxID   int64           `json:"robloxId"`
+	RobloxName string          `json:"robloxName"`
+	ItemType   string          `json:"itemType"`
+	ItemMatch  json.RawMessage `json:"itemData"`
+	PriceType  string          `json:"priceType"`
+	MaxPrice   int64           `json:"maxPrice"`
+	Created    time.Time       `json:"created"`
+}
+
+type AuctionOffer struct {
+	ID            int64     `json:"id"`
+	AuctionID     int64     `json:"auctionId"`
+	SellerID      int64     `json:"sellerId"`
+	RobloxID      int64     `json:"robloxId"`
+	RobloxName    string    `json:"robloxName"`
+	Amount        int64     `json:"amount"`
+	CounterAmount int64     `json:"counterAmount,omitempty"`
+	PriceType     string    `json:"priceType"`
+	Status        string    `json:"status"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	Created       time.Time `json:"created"`
+	Updated       time.Time `json:"updated"`
+}
+
+// AuctionBulkResult reports the outcome of one entry in a LIST_BULK or
+// UNLIST_BULK request.
+type AuctionBulkResult struct {
+	Index   int    `json:"index"`
+	UID     int64  `json:"id,omitempty"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
@@ -14,6 +14,49 @@ type Config struct {
 	V1Auth        string `json:"v1-auth"`
 	Prod          bool   `json:"PROD"`
 	Cron          string `json:"Cron"`
+	AdminAuth     string `json:"adminAuth"`
+
+	BidIncrementPercent int64            `json:"bidIncrementPercent"`
+	AuctionDurations    []int64          `json:"auctionDurations"`
+	ReservationSeconds  int64            `json:"reservationSeconds"`
+	ReservationCooldown int64            `json:"reservationCooldown"`
+	OfferSeconds        int64            `json:"offerSeconds"`
+	ListingLimits       map[string]int64 `json:"listingLimits"`
+	MailboxMaxAttempts  int              `json:"mailboxMaxAttempts"`
+	MailboxMode         string           `json:"mailboxMode"`
+	MailboxURL          string           `json:"mailboxUrl"`
+	MailboxAuth         string           `json:"mailboxAuth"`
+	MailboxTimeout      int64            `json:"mailboxTimeout"`
+	MarketKeyFields     []string         `json:"marketKeyFields"`
+	ProvenanceField     string           `json:"provenanceField"`
+
+	// ProceedsDeliveryDays is how long a seller has to claim proceeds before
+	// they are mailed automatically.
+	ProceedsDeliveryDays int64 `json:"proceedsDeliveryDays"`
+
+	// TradeSeconds is how long a trade waits for an answer before the offer
+	// is returned to the sender.
+	TradeSeconds int64 `json:"tradeSeconds"`
+
+	AuctionFees map[string]AuctionFee `json:"auctionFees"`
+	WashTrading WashTradingConfig     `json:"washTrading"`
+}
+
+// AuctionFee is the economy sink applied to one priceType: a percentage of
+// sale proceeds and a flat fee charged when an item is listed.
+type AuctionFee struct {
+	TaxPercent int64 `json:"taxPercent"`
+	ListingFee int64 `json:"listingFee"`
+}
+
+// WashTradingConfig holds the thresholds of the wash-trading analysis. Zero
+// values fall back to the defaults in storage.
+type WashTradingConfig struct {
+	Cron                   string `json:"cron"`
+	WindowDays             int64  `json:"windowDays"`
+	PairSales              int64  `json:"pairSales"`
+	MedianDeviationPercent int64  `json:"medianDeviationPercent"`
+	MinMarketSales         int64  `json:"minMarketSales"`
 }
 
 // NewConfig creates a configuration from file
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type EconomyReportRequest struct {
+	Payload   string `json:"payload"`
+	Days      int    `json:"days"`
+	PriceType string `json:"priceType"`
+}
+
+// EconomyDay is the auction house activity in one priceType on one day.
+type EconomyDay struct {
+	Day              time.Time `json:"day"`
+	PriceType        string    `json:"priceType"`
+	Listed           int64     `json:"listed"`
+	Sales            int64     `json:"sales"`
+	Unlisted         int64     `json:"unlisted"`
+	Expired          int64     `json:"expired"`
+	Volume           int64     `json:"volume"`
+	AverageSalePrice int64     `json:"averageSalePrice"`
+	TopItemType      string    `json:"topItemType,omitempty"`
+}
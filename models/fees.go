@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type AuctionFeeReport struct {
+	Payload   string `json:"payload"`
+	Days      int    `json:"days"`
+	PriceType string `json:"priceType"`
+}
+
+// AuctionFeeDay is the currency removed by one kind of fee on one day.
+type AuctionFeeDay struct {
+	Day       time.Time `json:"day"`
+	PriceType string    `json:"priceType"`
+	Kind      string    `json:"kind"`
+	Amount    int64     `json:"amount"`
+	Count     int64     `json:"count"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuctionFlag struct {
+	ID        int64           `json:"id"`
+	Kind      string          `json:"kind"`
+	ItemUUID  string          `json:"itemUuid,omitempty"`
+	AuctionID int64           `json:"auctionId,omitempty"`
+	RobloxID  int64           `json:"robloxId,omitempty"`
+	Evidence  json.RawMessage `json:"evidence"`
+	Status    string          `json:"status"`
+	Note      string          `json:"note,omitempty"`
+	Reviewer  string          `json:"reviewer,omitempty"`
+	Created   time.Time       `json:"created"`
+	Reviewed  *time.Time      `json:"reviewed,omitempty"`
+}
+
+type ProvenanceEntry struct {
+	ID        int64     `json:"id"`
+	ItemUUID  string    `json:"itemUuid"`
+	AuctionID int64     `json:"auctionId"`
+	Event     string    `json:"event"`
+	OwnerID   int64     `json:"ownerId"`
+	Created   time.Time `json:"created"`
+}
+
+type FlagRequest struct {
+	Payload  string `json:"payload"`
+	ID       int64  `json:"id"`
+	Kind     string `json:"kind"`
+	Status   string `json:"status"`
+	Note     string `json:"note"`
+	Reviewer string `json:"reviewer"`
+	ItemUUID string `json:"itemUuid"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type MailboxOutbox struct {
+	ID             int64           `json:"id"`
+	IdempotencyKey string          `json:"idempotencyKey"`
+	RobloxID       int64           `json:"robloxId"`
+	RobloxName     string          `json:"robloxName"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttempt    time.Time       `json:"nextAttempt"`
+	LastError      string          `json:"lastError,omitempty"`
+	Created        time.Time       `json:"created"`
+	Sent           *time.Time      `json:"sent,omitempty"`
+}
+
+type MailboxOutboxRequest struct {
+	Payload  string `json:"payload"`
+	ID       int64  `json:"id"`
+	RobloxID int64  `json:"robloxId"`
+	Status   string `json:"status"`
+}
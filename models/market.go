@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type MarketVolume struct {
+	Day   int64 `json:"day"`
+	Week  int64 `json:"week"`
+	Month int64 `json:"month"`
+}
+
+type MarketDay struct {
+	Day    time.Time `json:"day"`
+	Sales  int64     `json:"sales"`
+	Median int64     `json:"median"`
+	Min    int64     `json:"min"`
+	Max    int64     `json:"max"`
+}
+
+// MarketStats summarises completed sales of one item key in one currency.
+// Median, Min and Max cover the last 30 days.
+type MarketStats struct {
+	ItemType  string       `json:"itemType"`
+	ItemKey   string       `json:"itemKey"`
+	PriceType string       `json:"priceType"`
+	LastSale  int64        `json:"lastSale"`
+	LastSold  time.Time    `json:"lastSold"`
+	Median    int64        `json:"median"`
+	Min       int64        `json:"min"`
+	Max       int64        `json:"max"`
+	Volume    MarketVolume `json:"volume"`
+	Series    []*MarketDay `json:"series"`
+}
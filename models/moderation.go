@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// ModerationRequest drives /admin/moderation. id is the listing id for HIDE,
+// UNHIDE, REMOVE, DELETE and RESTORE and the ban id for UNBAN.
+type ModerationRequest struct {
+	Payload   string `json:"payload"`
+	ID        int64  `json:"id"`
+	RobloxID  int64  `json:"robloxId"`
+	Scope     string `json:"scope"`
+	Seconds   int64  `json:"seconds"`
+	Reason    string `json:"reason"`
+	Moderator string `json:"moderator"`
+}
+
+type AuctionBan struct {
+	ID         int64      `json:"id"`
+	RobloxID   int64      `json:"robloxId"`
+	Scope      string     `json:"scope"`
+	Reason     string     `json:"reason"`
+	Moderator  string     `json:"moderator"`
+	Until      *time.Time `json:"until,omitempty"`
+	Created    time.Time  `json:"created"`
+	Lifted     *time.Time `json:"lifted,omitempty"`
+	LiftedBy   string     `json:"liftedBy,omitempty"`
+	LiftReason string     `json:"liftReason,omitempty"`
+}
+
+type ModerationAction struct {
+	ID        int64     `json:"id"`
+	Action    string    `json:"action"`
+	RobloxID  int64     `json:"robloxId,omitempty"`
+	AuctionID int64     `json:"auctionId,omitempty"`
+	Reason    string    `json:"reason"`
+	Moderator string    `json:"moderator"`
+	Created   time.Time `json:"created"`
+}
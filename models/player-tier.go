@@ -0,0 +1,8 @@
+package models
+
+type PlayerTier struct {
+	Payload      string `json:"payload,omitempty"`
+	RobloxID     int64  `json:"robloxId"`
+	Tier         string `json:"tier"`
+	ListingLimit int64  `json:"listingLimit"`
+}
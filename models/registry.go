@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ItemTypeEntry struct {
+	Name    string          `json:"name"`
+	Schema  json.RawMessage `json:"schema"`
+	Active  bool            `json:"active"`
+	Updated time.Time       `json:"updated"`
+}
+
+type PriceTypeEntry struct {
+	Name    string    `json:"name"`
+	Active  bool      `json:"active"`
+	Updated time.Time `json:"updated"`
+}
+
+type Registry struct {
+	ItemTypes  []*ItemTypeEntry  `json:"itemTypes"`
+	PriceTypes []*PriceTypeEntry `json:"priceTypes"`
+}
+
+type RegistryRequest struct {
+	Payload string          `json:"payload"`
+	Name    string          `json:"name"`
+	Schema  json.RawMessage `json:"schema"`
+}
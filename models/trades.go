@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type TradeItem struct {
+	ItemType string          `json:"itemType"`
+	ItemData json.RawMessage `json:"itemData"`
+}
+
+// TradeBundle is one side of a trade: any number of items plus an optional
+// amount of a single currency.
+type TradeBundle struct {
+	Items     []TradeItem `json:"items"`
+	PriceType string      `json:"priceType,omitempty"`
+	Amount    int64       `json:"amount,omitempty"`
+}
+
+type Trade struct {
+	Payload    string `json:"payload,omitempty"`
+	ID         int64  `json:"id"`
+	RobloxID   int64  `json:"robloxId,omitempty"`
+	RobloxName string `json:"robloxName,omitempty"`
+
+	SenderID   int64       `json:"senderId"`
+	SenderName string      `json:"senderName"`
+	TargetID   int64       `json:"targetId"`
+	TargetName string      `json:"targetName"`
+	Offered    TradeBundle `json:"offered"`
+	Requested  TradeBundle `json:"requested"`
+	Status     string      `json:"status"`
+	Created    time.Time   `json:"created"`
+	Responded  *time.Time  `json:"responded,omitempty"`
+}
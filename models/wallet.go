@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type WalletBalance struct {
+	RobloxID  int64  `json:"robloxId"`
+	PriceType string `json:"priceType"`
+	Balance   int64  `json:"balance"`
+}
+
+type WalletLedgerEntry struct {
+	ID        int64     `json:"id"`
+	RobloxID  int64     `json:"robloxId"`
+	PriceType string    `json:"priceType"`
+	Amount    int64     `json:"amount"`
+	Balance   int64     `json:"balance"`
+	Reason    string    `json:"reason"`
+	AuctionID int64     `json:"auctionId,omitempty"`
+	Reference string    `json:"reference,omitempty"`
+	Created   time.Time `json:"created"`
+}
@@ -2,120 +2,182 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/kattah7/v3/models"
 )
 
-func (s *PostgresStore) ListAuction(item *models.AuctionAccount) error {
+// listingDuration checks a requested duration in seconds against the
+// configured set, defaulting to the first entry. Without a configured set
+// every listing gets the legacy global cutoff.
+func (s *PostgresStore) listingDuration(requested int64) (int64, error) {
+	if len(s.cfg.AuctionDurations) == 0 {
+		return s.legacyDuration(), nil
+	}
+
+	if requested == 0 {
+		return s.cfg.AuctionDurations[0], nil
+	}
+
+	for _, v := range s.cfg.AuctionDurations {
+		if v == requested {
+			return v, nil
+		}
+	}
+
+	return 0, fmt.Errorf("duration must be one of %v", s.cfg.AuctionDurations)
+}
+
+// legacyDuration is how long listings lived under the global cutoff, which
+// is configured as a negative offset from now.
+func (s *PostgresStore) legacyDuration() int64 {
+	if s.cfg.CutOffTime < 0 {
+		return -s.cfg.CutOffTime
+	}
+
+	return s.cfg.CutOffTime
+}
+
+// validateListing applies the LIST rules to item and returns the listing
+// duration in seconds.
+func (s *PostgresStore) validateListing(item *models.AuctionAccount) (int64, error) {
 	if item.ID == 0 && item.Name == "" {
-		return fmt.Errorf("robloxId or robloxName cannot be empty")
+		return 0, fmt.Errorf("robloxId or robloxName cannot be empty")
 	}
 
 	if item.ItemType == "" {
-		return fmt.Errorf("itemType cannot be empty")
+		return 0, fmt.Errorf("itemType cannot be empty")
 	}
 
-	if item.ItemType != "EGG" && item.ItemType != "PET" && item.ItemType != "BOOST" && item.ItemType != "POTION" {
-		return fmt.Errorf("itemType must be EGG, PET, BOOST, or POTION")
+	if item.ItemData == nil {
+		return 0, fmt.Errorf("itemData cannot be empty")
 	}
 
-	if item.ItemData == nil {
-		return fmt.Errorf("itemData cannot be empty")
+	if err := s.checkItem(item.ItemType, item.ItemData); err != nil {
+		return 0, err
 	}
 
-	fmt.Println(item.PriceType)
-	if item.Price == 0 {
-		return fmt.Errorf("price cannot be empty")
+	if item.Price <= 0 {
+		return 0, fmt.Errorf("price must be greater than 0")
 	}
 
-	if item.PriceType != "Diamonds" && item.PriceType != "Coins" && item.PriceType != "DarkCoins" && item.PriceType != "Pearls" && item.PriceType != "Candy" && item.PriceType != "Chocolate" {
-		return fmt.Errorf("priceType must be Diamonds, Coins, DarkCoins, Pearls, Candy, or Chocolate")
+	if err := s.checkPriceType(item.PriceType); err != nil {
+		return 0, err
 	}
 
-	checkQuery := `SELECT robloxId FROM auctions WHERE robloxId = $1 AND status = 'OPEN'`
-	rows, err := s.db.Query(context.Background(), checkQuery, item.ID)
-	if err != nil {
-		return fmt.Errorf("Unable to query row: %w", err)
+	if err := validateAuctionMode(item); err != nil {
+		return 0, err
 	}
 
-	defer rows.Close()
+	return s.listingDuration(item.Duration)
+}
 
-	count := 0
-	for rows.Next() {
-		count++
-		if count >= 5 {
-			return fmt.Errorf("Exceeded maximum allowed rows")
-		}
+func (s *PostgresStore) ListAuction(item *models.AuctionAccount) error {
+	duration, err := s.validateListing(item)
+	if err != nil {
+		return err
 	}
 
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("Error iterating rows: %w", err)
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("Unable to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	query := `
-	INSERT INTO auctions (robloxId, robloxName, itemType, itemData, startPrice, priceType)
-	VALUES ($1, $2, $3, $4, $5, $6)
-	`
+	if err := s.checkListingLimit(ctx, tx, item.ID, item.Tier, 1); err != nil {
+		return err
+	}
 
-	_, err2 := s.db.Exec(context.Background(), query, item.ID, item.Name, item.ItemType, item.ItemData, item.Price, item.PriceType)
-	if err2 != nil {
-		return fmt.Errorf("unable to insert row: %w", err2)
+	if _, err := s.insertListing(ctx, tx, item, duration); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("Unable to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
-func (s *PostgresStore) PurchaseAuction(item *models.AuctionAccount) error {
-	if item.UID == 0 {
-		return fmt.Errorf("id cannot be empty")
+// insertListing creates an OPEN listing inside tx, charges the listing fee,
+// records it and notifies matching alerts. It returns the new listing id.
+func (s *PostgresStore) insertListing(ctx context.Context, tx pgx.Tx, item *models.AuctionAccount, duration int64) (int64, error) {
+	if err := checkBan(ctx, tx, item.ID, "LIST"); err != nil {
+		return 0, err
 	}
 
-	query := `UPDATE auctions SET status = 'PURCHASED' WHERE id = $1 AND status = 'OPEN'`
+	query := `
+	INSERT INTO auctions (robloxId, robloxName, itemType, itemData, startPrice, priceType, expiresAt, mode, floorPrice, priceStep, stepSeconds, reservePrice)
+	VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP + make_interval(secs => $7), $8, $9, $10, $11, $12)
+	RETURNING id
+	`
 
-	result, err := s.db.Exec(context.Background(), query, item.UID)
+	var uid int64
+	err := tx.QueryRow(ctx, query, item.ID, item.Name, item.ItemType, item.ItemData, item.Price, item.PriceType, duration,
+		item.Mode, item.FloorPrice, item.PriceStep, item.StepSeconds, item.ReservePrice).Scan(&uid)
 	if err != nil {
-		return fmt.Errorf("Unable to update row: %w", err)
+		return 0, fmt.Errorf("unable to insert row: %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("No rows affected")
+	if err := s.chargeListingFee(ctx, tx, uid, item); err != nil {
+		return 0, err
 	}
 
-	return nil
-}
+	if err := s.checkProvenance(ctx, tx, uid, item.ID); err != nil {
+		return 0, err
+	}
 
-func (s *PostgresStore) RemoveAuction(item *models.AuctionAccount) error {
-	query := `DELETE FROM auctions WHERE id = $1`
+	if err := recordAuctionEvent(ctx, tx, uid, "LISTED", item.ID, item.Name, ""); err != nil {
+		return 0, err
+	}
+
+	if err := notifyAuctionAlerts(ctx, tx, uid, item); err != nil {
+		return 0, err
+	}
+
+	return uid, nil
+}
 
-	result, err := s.db.Exec(context.Background(), query, item.UID)
+const auctionColumns = `id, robloxId, robloxName, itemType, itemData, startPrice, priceType, listed, highestBid, highestBidderId, highestBidderName, buyerId, buyerName, purchased, soldPrice, escrowed, tax, expiresAt, expiredReason,
+	reservedBy <> 0 AND reservedUntil > CURRENT_TIMESTAMP, reservedUntil, hidden,
+	mode, floorPrice, priceStep, stepSeconds, ` + currentPriceExpr + `, ` + reserveMetExpr
+
+func scanAuction(row pgx.Row, item *models.AuctionAccount) error {
+	err := row.Scan(
+		&item.UID, &item.ID, &item.Name, &item.ItemType, &item.ItemData, &item.Price, &item.PriceType, &item.ListedDate,
+		&item.HighestBid, &item.HighestBidderID, &item.HighestBidderName,
+		&item.BuyerID, &item.BuyerName, &item.PurchasedDate, &item.SoldPrice, &item.Escrowed, &item.Tax,
+		&item.ExpiresAt, &item.ExpiredReason,
+		&item.Reserved, &item.ReservedUntil, &item.Hidden,
+		&item.Mode, &item.FloorPrice, &item.PriceStep, &item.StepSeconds, &item.CurrentPrice, &item.ReserveMet,
+	)
 	if err != nil {
-		return fmt.Errorf("Unable to delete row: %w", err)
+		return err
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("No rows affected")
+	if item.SoldPrice > 0 {
+		item.NetProceeds = item.SoldPrice - item.Tax
 	}
 
 	return nil
 }
 
-func (s *PostgresStore) GetAuctions() ([]*models.AuctionAccount, error) {
-	query := `SELECT id, robloxId, robloxName, itemType, itemData, startPrice, priceType, listed FROM auctions WHERE status = 'OPEN' ORDER BY id DESC`
-
-	rows, err := s.db.Query(context.Background(), query)
+func (s *PostgresStore) queryAuctions(query string, args ...any) ([]*models.AuctionAccount, error) {
+	rows, err := s.db.Query(context.Background(), query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to query row: %w", err)
 	}
 
 	defer rows.Close()
 
-	var auctions []*models.AuctionAccount
+	auctions := make([]*models.AuctionAccount, 0)
 
 	for rows.Next() {
 		item := &models.AuctionAccount{}
-		err := rows.Scan(&item.UID, &item.ID, &item.Name, &item.ItemType, &item.ItemData, &item.Price, &item.PriceType, &item.ListedDate)
-		if err != nil {
+		if err := scanAuction(rows, item); err != nil {
 			return nil, fmt.Errorf("Unable to scan row: %w", err)
 		}
 
@@ -129,120 +191,265 @@ func (s *PostgresStore) GetAuctions() ([]*models.AuctionAccount, error) {
 	return auctions, nil
 }
 
-func (s *PostgresStore) GetAuctionClaims(item *models.AuctionAccount) ([]*models.AuctionAccount, error) {
-	if item.ID == 0 {
-		return nil, fmt.Errorf("robloxId cannot be empty")
+func (s *PostgresStore) PurchaseAuction(item *models.AuctionAccount) (*models.AuctionAccount, error) {
+	return s.purchaseAuction(item, false)
+}
+
+// purchaseAuction sells an OPEN listing to the buyer in item. A listing that
+// another buyer holds a live reservation on can't be bought; with confirm set
+// the buyer must hold that reservation themselves.
+func (s *PostgresStore) purchaseAuction(item *models.AuctionAccount, confirm bool) (*models.AuctionAccount, error) {
+	if item.UID == 0 {
+		return nil, fmt.Errorf("id cannot be empty")
 	}
 
-	query := `SELECT id, robloxId, robloxName, itemType, itemData, startPrice, priceType FROM auctions WHERE status = 'PURCHASED' AND robloxId = $1 ORDER BY id DESC`
+	if item.ID == 0 || item.Name == "" {
+		return nil, fmt.Errorf("robloxId and robloxName cannot be empty")
+	}
 
-	rows, err := s.db.Query(context.Background(), query, item.ID)
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to query row: %w", err)
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	defer rows.Close()
+	if err := checkBan(ctx, tx, item.ID, "BUY"); err != nil {
+		return nil, err
+	}
 
-	var auctions []*models.AuctionAccount
+	reservation := `(reservedBy = 0 OR reservedBy = $2 OR reservedUntil <= CURRENT_TIMESTAMP)`
+	if confirm {
+		reservation = `reservedBy = $2 AND reservedUntil > CURRENT_TIMESTAMP`
+	}
 
-	for rows.Next() {
-		item := &models.AuctionAccount{}
-		err := rows.Scan(&item.UID, &item.ID, &item.Name, &item.ItemType, &item.ItemData, &item.Price, &item.PriceType)
-		if err != nil {
-			return nil, fmt.Errorf("Unable to scan row: %w", err)
-		}
+	// Once bidding has started the listing can only be won through a bid, and
+	// reserve-price listings are only sold by bid. Dutch listings sell at the
+	// price current when the row is updated, whatever the client last saw.
+	query := `
+	UPDATE auctions SET status = 'PURCHASED', buyerId = $2, buyerName = $3, purchased = CURRENT_TIMESTAMP,
+		soldPrice = ` + currentPriceExpr + `, escrowed = ` + currentPriceExpr + `, reservedBy = 0, reservedUntil = NULL
+	WHERE id = $1 AND status = 'OPEN' AND NOT hidden AND mode <> 'RESERVE' AND expiresAt > CURRENT_TIMESTAMP AND highestBidderId = 0 AND robloxId <> $2 AND ` + reservation + `
+	RETURNING ` + auctionColumns
 
-		auctions = append(auctions, item)
+	purchased := &models.AuctionAccount{}
+	err = scanAuction(tx.QueryRow(ctx, query, item.UID, item.ID, item.Name), purchased)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("auction is not available for purchase")
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
 	}
 
-	if len(auctions) == 0 {
-		emptyArray := make([]*models.AuctionAccount, 0)
+	if err := s.settlePurchase(ctx, tx, purchased, ""); err != nil {
+		return nil, err
+	}
 
-		return emptyArray, nil
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
 	}
 
-	return auctions, nil
+	return purchased, nil
 }
 
-func (s *PostgresStore) AuctionClaim(item *models.AuctionAccount) error {
-	if item.UID == 0 {
-		return fmt.Errorf("uid cannot be empty")
+// settlePurchase records a completed buy-now sale, moves the buyer's payment
+// into escrow and cancels any offers still open on the listing.
+func (s *PostgresStore) settlePurchase(ctx context.Context, tx pgx.Tx, purchased *models.AuctionAccount, detail string) error {
+	if purchased.Escrowed <= 0 {
+		return fmt.Errorf("purchase price must be greater than 0")
 	}
 
-	if item.ID == 0 {
-		return fmt.Errorf("id cannot be empty")
+	if err := recordAuctionEvent(ctx, tx, purchased.UID, "PURCHASED", purchased.BuyerID, purchased.BuyerName, detail); err != nil {
+		return err
 	}
 
-	checkQuery := `SELECT robloxId FROM auctions WHERE id = $1 AND status = 'PURCHASED'`
+	if err := s.recordSale(ctx, tx, purchased.UID); err != nil {
+		return err
+	}
 
-	var robloxId int64
-	err := s.db.QueryRow(context.Background(), checkQuery, item.UID).Scan(&robloxId)
+	if err := s.recordProvenance(ctx, tx, purchased.UID, "PURCHASED", purchased.BuyerID); err != nil {
+		return err
+	}
 
+	// The buyer pays into escrow; the seller is paid out when they claim.
+	if _, err := adjustWallet(ctx, tx, purchased.BuyerID, purchased.PriceType, -purchased.Escrowed, "AUCTION_PURCHASE", purchased.UID, ""); err != nil {
+		return err
+	}
+
+	if _, err := adjustWallet(ctx, tx, escrowAccount, purchased.PriceType, purchased.Escrowed, "ESCROW_HOLD", purchased.UID, ""); err != nil {
+		return err
+	}
+
+	return cancelPendingOffers(ctx, tx, purchased.UID)
+}
+
+// closeAuction records event, runs query against the listing and cancels its
+// standing bid and open offers, all in the same transaction.
+func (s *PostgresStore) closeAuction(uid int64, query string, event string, actorId int64, actorName string) error {
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("Unable to query row: %w", err)
+		return fmt.Errorf("Unable to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	if robloxId != item.ID {
-		return fmt.Errorf("robloxId does not match with id")
+	if err := closeAuctionTx(ctx, tx, uid, query, event, actorId, actorName, ""); err != nil {
+		return err
 	}
 
-	query := `DELETE FROM auctions WHERE id = $1`
-	result, err := s.db.Exec(context.Background(), query, item.UID)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("Unable to commit transaction: %w", err)
+	}
 
+	return nil
+}
+
+func closeAuctionTx(ctx context.Context, tx pgx.Tx, uid int64, query string, event string, actorId int64, actorName string, detail string) error {
+	if err := recordAuctionEvent(ctx, tx, uid, event, actorId, actorName, detail); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, query, uid)
 	if err != nil {
-		return fmt.Errorf("Unable to delete row: %w", err)
+		return fmt.Errorf("Unable to update row: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
 		return fmt.Errorf("No rows affected")
 	}
 
-	return nil
+	if _, err := cancelActiveBid(ctx, tx, uid, "CANCELLED"); err != nil {
+		return err
+	}
+
+	return cancelPendingOffers(ctx, tx, uid)
 }
 
-func (s *PostgresStore) GetAuctionListing(item *models.AuctionAccount) ([]*models.AuctionAccount, error) {
+// GetAuctionClaims splits what a player is owed into items they bought and
+// currency they earned from their own listings.
+func (s *PostgresStore) GetAuctionClaims(item *models.AuctionAccount) (*models.AuctionClaims, error) {
 	if item.ID == 0 {
 		return nil, fmt.Errorf("robloxId cannot be empty")
 	}
 
-	query := `SELECT id, robloxId, robloxName, itemType, itemData, startPrice, priceType, listed FROM auctions WHERE status = 'OPEN' AND robloxId = $1 ORDER BY id DESC`
+	itemsQuery := `SELECT ` + auctionColumns + ` FROM auctions WHERE status = 'PURCHASED' AND buyerId = $1 AND NOT itemClaimed ORDER BY id DESC`
+	items, err := s.queryAuctions(itemsQuery, item.ID)
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := s.db.Query(context.Background(), query, item.ID)
+	proceedsQuery := `SELECT ` + auctionColumns + ` FROM auctions WHERE status = 'PURCHASED' AND robloxId = $1 AND NOT proceedsClaimed ORDER BY id DESC`
+	proceeds, err := s.queryAuctions(proceedsQuery, item.ID)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to query row: %w", err)
+		return nil, err
 	}
 
-	defer rows.Close()
+	// Proceeds are taxed when claimed; show what the seller will receive.
+	for _, listing := range proceeds {
+		listing.Tax = s.saleTax(listing.PriceType, listing.SoldPrice)
+		listing.NetProceeds = listing.SoldPrice - listing.Tax
+	}
 
-	var auctions []*models.AuctionAccount
+	return &models.AuctionClaims{
+		Items:    items,
+		Proceeds: proceeds,
+	}, nil
+}
 
-	for rows.Next() {
-		item := &models.AuctionAccount{}
-		err := rows.Scan(&item.UID, &item.ID, &item.Name, &item.ItemType, &item.ItemData, &item.Price, &item.PriceType, &item.ListedDate)
-		if err != nil {
-			return nil, fmt.Errorf("Unable to scan row: %w", err)
+// AuctionClaim marks one side of a sale as delivered. The buyer claims the
+// ITEM and the seller claims the PROCEEDS; once both are delivered the row is
+// kept as CLAIMED so the trade can still be audited.
+func (s *PostgresStore) AuctionClaim(item *models.AuctionAccount) (*models.AuctionAccount, error) {
+	if item.UID == 0 {
+		return nil, fmt.Errorf("uid cannot be empty")
+	}
+
+	if item.ID == 0 {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	claimType := item.ClaimType
+	if claimType == "" {
+		claimType = "PROCEEDS"
+	}
+
+	var query string
+	switch claimType {
+	case "ITEM":
+		query = `
+		UPDATE auctions SET itemClaimed = true, status = CASE WHEN proceedsClaimed THEN 'CLAIMED' ELSE status END
+		WHERE id = $1 AND status = 'PURCHASED' AND buyerId = $2 AND NOT itemClaimed
+		RETURNING ` + auctionColumns
+	case "PROCEEDS":
+		query = `
+		UPDATE auctions SET proceedsClaimed = true, status = CASE WHEN itemClaimed THEN 'CLAIMED' ELSE status END
+		WHERE id = $1 AND status = 'PURCHASED' AND robloxId = $2 AND NOT proceedsClaimed
+		RETURNING ` + auctionColumns
+	default:
+		return nil, fmt.Errorf("claimType must be ITEM or PROCEEDS")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	claimed := &models.AuctionAccount{}
+	err = scanAuction(tx.QueryRow(ctx, query, item.UID, item.ID), claimed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("nothing to claim for robloxId on this id")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	if err := recordAuctionEvent(ctx, tx, claimed.UID, "CLAIMED", item.ID, item.Name, claimType); err != nil {
+		return nil, err
+	}
+
+	switch claimType {
+	case "ITEM":
+		if err := s.recordProvenance(ctx, tx, claimed.UID, "CLAIMED", claimed.BuyerID); err != nil {
+			return nil, err
+		}
+	case "PROCEEDS":
+		if err := s.collectSaleTax(ctx, tx, claimed, true); err != nil {
+			return nil, err
 		}
+	}
 
-		auctions = append(auctions, item)
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	return claimed, nil
+}
+
+func (s *PostgresStore) GetAuctionListing(item *models.AuctionAccount) ([]*models.AuctionAccount, error) {
+	if item.ID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
 	}
 
-	if len(auctions) == 0 {
-		emptyArray := make([]*models.AuctionAccount, 0)
+	query := `SELECT ` + auctionColumns + ` FROM auctions WHERE status = 'OPEN' AND robloxId = $1 ORDER BY id DESC`
 
-		return emptyArray, nil
+	listings, err := s.queryAuctions(query, item.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	return auctions, nil
+	if err := s.attachPriceHistory(listings); err != nil {
+		return nil, err
+	}
+
+	return listings, nil
 }
 
+// unlistQuery removes an OPEN listing. A buyer holding a reservation is about
+// to confirm, so the seller has to wait; a hidden listing is under review.
+const unlistQuery = `DELETE FROM auctions WHERE id = $1 AND status = 'OPEN' AND NOT hidden AND (reservedBy = 0 OR reservedUntil <= CURRENT_TIMESTAMP)`
+
 func (s *PostgresStore) AuctionUnlist(item *models.AuctionAccount) error {
 	if item.UID == 0 {
 		return fmt.Errorf("uid cannot be empty")
@@ -265,92 +472,156 @@ func (s *PostgresStore) AuctionUnlist(item *models.AuctionAccount) error {
 		return fmt.Errorf("robloxId does not match with id")
 	}
 
-	query := `DELETE FROM auctions WHERE id = $1`
-	result, err := s.db.Exec(context.Background(), query, item.UID)
+	return s.closeAuction(item.UID, unlistQuery, "UNLISTED", item.ID, item.Name)
+}
+
+// expireAuctions closes listings whose duration has run out. Listings with a
+// standing bid go to the highest bidder, everything else is returned to the
+// seller and kept as EXPIRED.
+func (s *PostgresStore) expireAuctions() {
+	query := `SELECT id FROM auctions WHERE expiresAt <= CURRENT_TIMESTAMP AND status = 'OPEN' AND NOT hidden`
 
+	rows, err := s.db.Query(context.Background(), query)
 	if err != nil {
-		return fmt.Errorf("Unable to delete row: %w", err)
+		fmt.Printf("unable to query database: %v\n", err)
+		return
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("No rows affected")
+	var expired []int64
+	for rows.Next() {
+		var uid int64
+		if err := rows.Scan(&uid); err != nil {
+			rows.Close()
+			fmt.Printf("unable to query database: %v\n", err)
+			return
+		}
+
+		expired = append(expired, uid)
 	}
+	rows.Close()
 
-	return nil
+	for _, uid := range expired {
+		if err := s.expireAuction(uid); err != nil {
+			fmt.Printf("Failed to expire auction %d: %v\n", uid, err)
+		}
+	}
 }
 
-// func (s *PostgresStore) AuctionExpireList(item *models.AuctionAccount) ([]*models.AuctionAccount, error) {
-// 	if item.ID == 0 {
-// 		return nil, fmt.Errorf("robloxId cannot be empty")
-// 	}
+// expireAuction closes a single listing and queues its mailbox delivery in the
+// same transaction, so an item is never closed without being delivered.
+func (s *PostgresStore) expireAuction(uid int64) error {
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-// 	query := `SELECT * FROM auction_expired WHERE robloxId = $1 ORDER BY id DESC`
+	query := `
+	SELECT robloxId, robloxName, itemData, highestBidderId, highestBidderName, highestBid >= reservePrice FROM auctions
+	WHERE id = $1 AND status = 'OPEN' AND NOT hidden AND expiresAt <= CURRENT_TIMESTAMP
+	FOR UPDATE
+	`
 
-// 	rows, err := s.db.Query(context.Background(), query, item.ID)
+	var robloxId, highestBidderId int64
+	var robloxName, highestBidderName string
+	var itemData map[string]interface{}
+	var reserveMet bool
 
-// 	if err != nil {
-// 		return nil, fmt.Errorf("Unable to query row: %w", err)
-// 	}
+	err = tx.QueryRow(ctx, query, uid).Scan(&robloxId, &robloxName, &itemData, &highestBidderId, &highestBidderName, &reserveMet)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
 
-// 	defer rows.Close()
+	var mail *models.MailboxExpire
+	var idempotencyKey string
 
-// 	var auctions []*models.AuctionAccount
+	if highestBidderId != 0 && reserveMet {
+		// The winning bid is already held in escrow, so the seller is paid out
+		// of it on claim like a buy-now sale. Bids placed before bids were
+		// escrowed leave escrowed at 0.
+		var bidEscrowed bool
+		err := tx.QueryRow(ctx, `UPDATE auction_bids SET status = 'WON' WHERE auctionId = $1 AND status = 'ACTIVE' RETURNING escrowed`, uid).Scan(&bidEscrowed)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("Unable to update row: %w", err)
+		}
 
-// 	for rows.Next() {
-// 		item := &models.AuctionAccount{}
-// 		err := rows.Scan(&item.UID, &item.ID, &item.Name, &item.ItemType, &item.ItemData)
-// 		if err != nil {
-// 			return nil, fmt.Errorf("Unable to scan row: %w", err)
-// 		}
+		awardQuery := `
+		UPDATE auctions SET status = 'PURCHASED', buyerId = highestBidderId, buyerName = highestBidderName,
+			purchased = CURRENT_TIMESTAMP, soldPrice = highestBid, escrowed = CASE WHEN $2 THEN highestBid ELSE 0 END, itemClaimed = true
+		WHERE id = $1
+		`
 
-// 		auctions = append(auctions, item)
-// 	}
+		if _, err := tx.Exec(ctx, awardQuery, uid, bidEscrowed); err != nil {
+			return fmt.Errorf("Unable to update row: %w", err)
+		}
 
-// 	if err := rows.Err(); err != nil {
-// 		return nil, fmt.Errorf("Error iterating rows: %w", err)
-// 	}
+		if err := recordAuctionEvent(ctx, tx, uid, "PURCHASED", highestBidderId, highestBidderName, "WON_BY_BID"); err != nil {
+			return err
+		}
 
-// 	if len(auctions) == 0 {
-// 		emptyArray := make([]*models.AuctionAccount, 0)
+		if err := s.recordSale(ctx, tx, uid); err != nil {
+			return err
+		}
 
-// 		return emptyArray, nil
-// 	}
+		if err := s.recordProvenance(ctx, tx, uid, "WON", highestBidderId); err != nil {
+			return err
+		}
 
-// 	return auctions, nil
-// }
+		mail, err = newMailbox(highestBidderId, highestBidderName, "You won the auction! The item has been sent to your mailbox.", itemData)
+		idempotencyKey = fmt.Sprintf("auction:%d:won", uid)
+	} else {
+		reason := "DURATION_ELAPSED"
+		if highestBidderId != 0 {
+			reason = "RESERVE_NOT_MET"
+			if _, err := cancelActiveBid(ctx, tx, uid, "CANCELLED"); err != nil {
+				return err
+			}
+		}
 
-// func (s *PostgresStore) AuctionExpireClaim(item *models.AuctionAccount) error {
-// 	if item.UID == 0 {
-// 		return fmt.Errorf("uid cannot be empty")
-// 	}
+		expireQuery := `UPDATE auctions SET status = 'EXPIRED', expiredReason = $2, expired = CURRENT_TIMESTAMP WHERE id = $1`
+		if _, err := tx.Exec(ctx, expireQuery, uid, reason); err != nil {
+			return fmt.Errorf("Unable to update row: %w", err)
+		}
 
-// 	if item.ID == 0 {
-// 		return fmt.Errorf("id cannot be empty")
-// 	}
+		if err := recordAuctionEvent(ctx, tx, uid, "EXPIRED", 0, systemActor, reason); err != nil {
+			return err
+		}
+
+		mail, err = newMailbox(robloxId, robloxName, "This item has expired and has been returned to your mailbox.", itemData)
+		idempotencyKey = fmt.Sprintf("auction:%d:expired", uid)
+	}
 
-// 	checkQuery := `SELECT robloxId FROM auction_expired WHERE id = $1`
+	if err != nil {
+		return err
+	}
 
-// 	var robloxId int64
-// 	err := s.db.QueryRow(context.Background(), checkQuery, item.UID).Scan(&robloxId)
+	if err := enqueueMailbox(ctx, tx, idempotencyKey, mail); err != nil {
+		return err
+	}
 
-// 	if err != nil {
-// 		return fmt.Errorf("Unable to query row: %w", err)
-// 	}
+	if err := cancelPendingOffers(ctx, tx, uid); err != nil {
+		return err
+	}
 
-// 	if robloxId != item.ID {
-// 		return fmt.Errorf("robloxId does not match with id")
-// 	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("Unable to commit transaction: %w", err)
+	}
 
-// 	query := `DELETE FROM auction_expired WHERE id = $1`
-// 	result, err := s.db.Exec(context.Background(), query, item.UID)
+	return nil
+}
 
-// 	if err != nil {
-// 		return fmt.Errorf("Unable to delete row: %w", err)
-// 	}
+// AuctionExpireList shows a seller the listings that expired without a sale
+// and were returned to their mailbox.
+func (s *PostgresStore) AuctionExpireList(item *models.AuctionAccount) ([]*models.AuctionAccount, error) {
+	if item.ID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
 
-// 	if result.RowsAffected() == 0 {
-// 		return fmt.Errorf("No rows affected")
-// 	}
+	query := `SELECT ` + auctionColumns + ` FROM auctions WHERE status = 'EXPIRED' AND robloxId = $1 ORDER BY id DESC LIMIT $2`
 
-// 	return nil
-// }
+	return s.queryAuctions(query, item.ID, LIMIT)
+}
@@ -0,0 +1,321 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+const (
+	maxWatchlist = 50
+	maxAlerts    = 20
+)
+
+func (s *PostgresStore) WatchAuction(item *models.AuctionAccount) error {
+	if item.UID == 0 {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	if item.ID == 0 {
+		return fmt.Errorf("robloxId cannot be empty")
+	}
+
+	var count int64
+	if err := s.db.QueryRow(context.Background(), `SELECT COUNT(*) FROM auction_watchlist WHERE robloxId = $1`, item.ID).Scan(&count); err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	if count >= maxWatchlist {
+		return fmt.Errorf("watchlist cannot hold more than %d listings", maxWatchlist)
+	}
+
+	query := `
+	INSERT INTO auction_watchlist (robloxId, auctionId)
+	SELECT $1, id FROM auctions WHERE id = $2 AND status = 'OPEN'
+	ON CONFLICT (robloxId, auctionId) DO NOTHING
+	`
+
+	result, err := s.db.Exec(context.Background(), query, item.ID, item.UID)
+	if err != nil {
+		return fmt.Errorf("unable to insert row: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("auction is not open or already watched")
+	}
+
+	return nil
+}
+
+func (s *PostgresStore) UnwatchAuction(item *models.AuctionAccount) error {
+	if item.UID == 0 {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	if item.ID == 0 {
+		return fmt.Errorf("robloxId cannot be empty")
+	}
+
+	result, err := s.db.Exec(context.Background(), `DELETE FROM auction_watchlist WHERE robloxId = $1 AND auctionId = $2`, item.ID, item.UID)
+	if err != nil {
+		return fmt.Errorf("Unable to delete row: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("No rows affected")
+	}
+
+	return nil
+}
+
+// GetWatchlist returns the current state of every listing the player watches,
+// whatever its status, so they can see when one sells or expires.
+func (s *PostgresStore) GetWatchlist(item *models.AuctionAccount) ([]*models.AuctionAccount, error) {
+	if item.ID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	query := `SELECT ` + auctionColumns + ` FROM auctions WHERE id IN (SELECT auctionId FROM auction_watchlist WHERE robloxId = $1) ORDER BY id DESC`
+
+	return s.queryAuctions(query, item.ID)
+}
+
+// CreateAuctionAlert registers a standing alert. itemData is matched by JSONB
+// containment, so {"petId": "X"} matches every listing of pet X.
+func (s *PostgresStore) CreateAuctionAlert(item *models.AuctionAccount) (*models.AuctionAlert, error) {
+	if item.ID == 0 || item.Name == "" {
+		return nil, fmt.Errorf("robloxId and robloxName cannot be empty")
+	}
+
+	if item.ItemType == "" {
+		return nil, fmt.Errorf("itemType cannot be empty")
+	}
+
+	if err := s.checkPriceType(item.PriceType); err != nil {
+		return nil, err
+	}
+
+	if item.MaxPrice <= 0 {
+		return nil, fmt.Errorf("maxPrice must be greater than 0")
+	}
+
+	itemMatch := json.RawMessage(`{}`)
+	if len(item.ItemData) > 0 && string(item.ItemData) != "null" {
+		var match map[string]interface{}
+		if err := json.Unmarshal(item.ItemData, &match); err != nil {
+			return nil, fmt.Errorf("itemData must be a JSON object")
+		}
+
+		itemMatch = item.ItemData
+	}
+
+	var count int64
+	if err := s.db.QueryRow(context.Background(), `SELECT COUNT(*) FROM auction_alerts WHERE robloxId = $1`, item.ID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	if count >= maxAlerts {
+		return nil, fmt.Errorf("cannot have more than %d alerts", maxAlerts)
+	}
+
+	alert := &models.AuctionAlert{
+		RobloxID:   item.ID,
+		RobloxName: item.Name,
+		ItemType:   item.ItemType,
+		ItemMatch:  itemMatch,
+		PriceType:  item.PriceType,
+		MaxPrice:   item.MaxPrice,
+	}
+
+	query := `
+	INSERT INTO auction_alerts (robloxId, robloxName, itemType, itemMatch, priceType, maxPrice)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id, created
+	`
+
+	err := s.db.QueryRow(context.Background(), query, alert.RobloxID, alert.RobloxName, alert.ItemType, alert.ItemMatch, alert.PriceType, alert.MaxPrice).Scan(&alert.ID, &alert.Created)
+	if err != nil {
+		return nil, fmt.Errorf("unable to insert row: %w", err)
+	}
+
+	return alert, nil
+}
+
+// DeleteAuctionAlert removes the alert whose id is passed in id.
+func (s *PostgresStore) DeleteAuctionAlert(item *models.AuctionAccount) error {
+	if item.UID == 0 {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	if item.ID == 0 {
+		return fmt.Errorf("robloxId cannot be empty")
+	}
+
+	result, err := s.db.Exec(context.Background(), `DELETE FROM auction_alerts WHERE id = $1 AND robloxId = $2`, item.UID, item.ID)
+	if err != nil {
+		return fmt.Errorf("Unable to delete row: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("No rows affected")
+	}
+
+	return nil
+}
+
+func (s *PostgresStore) GetAuctionAlerts(item *models.AuctionAccount) ([]*models.AuctionAlert, error) {
+	if item.ID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	query := `SELECT id, robloxId, robloxName, itemType, itemMatch, priceType, maxPrice, created FROM auction_alerts WHERE robloxId = $1 ORDER BY id DESC`
+
+	rows, err := s.db.Query(context.Background(), query, item.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	alerts := make([]*models.AuctionAlert, 0)
+
+	for rows.Next() {
+		alert := &models.AuctionAlert{}
+		if err := rows.Scan(&alert.ID, &alert.RobloxID, &alert.RobloxName, &alert.ItemType, &alert.ItemMatch, &alert.PriceType, &alert.MaxPrice, &alert.Created); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// notifyAuctionAlerts queues a mailbox notice for every alert the new listing
+// matches, inside the transaction that creates the listing.
+func notifyAuctionAlerts(ctx context.Context, tx pgx.Tx, uid int64, item *models.AuctionAccount) error {
+	query := `
+	SELECT id, robloxId, robloxName FROM auction_alerts
+	WHERE itemType = $1 AND priceType = $2 AND maxPrice >= $3 AND $4::jsonb @> itemMatch AND robloxId <> $5
+	`
+
+	rows, err := tx.Query(ctx, query, item.ItemType, item.PriceType, item.Price, string(item.ItemData), item.ID)
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	var matches []*models.AuctionAlert
+	for rows.Next() {
+		alert := &models.AuctionAlert{}
+		if err := rows.Scan(&alert.ID, &alert.RobloxID, &alert.RobloxName); err != nil {
+			rows.Close()
+			return fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		matches = append(matches, alert)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	for _, alert := range matches {
+		if err := queueAlertNotice(ctx, tx, alert, uid, item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queueAlertNotice mails one alert's owner about a matching listing. Each
+// alert is notified about a listing at most once.
+func queueAlertNotice(ctx context.Context, tx pgx.Tx, alert *models.AuctionAlert, uid int64, item *models.AuctionAccount) error {
+	notice := map[string]interface{}{
+		"itemType":        "AUCTION_ALERT",
+		"auctionId":       uid,
+		"listingItemType": item.ItemType,
+		"listingItemData": item.ItemData,
+		"priceType":       item.PriceType,
+		"price":           item.Price,
+	}
+
+	mail, err := newMailbox(alert.RobloxID, alert.RobloxName, fmt.Sprintf("An item you are watching for was listed for %d %s.", item.Price, item.PriceType), notice)
+	if err != nil {
+		return err
+	}
+
+	return enqueueMailbox(ctx, tx, fmt.Sprintf("alert:%d:auction:%d", alert.ID, uid), mail)
+}
+
+// notifyDutchAlerts notifies alerts that an OPEN Dutch listing has dropped
+// under since it was listed. notifyAuctionAlerts only sees the opening price.
+func (s *PostgresStore) notifyDutchAlerts() {
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("Failed to notify alerts:", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	SELECT al.id, al.robloxId, al.robloxName, a.id, a.itemType, a.itemData, a.priceType, a.price
+	FROM (
+		SELECT id, robloxId, itemType, itemData, priceType, ` + currentPriceExpr + ` AS price FROM auctions
+		WHERE mode = 'DUTCH' AND status = 'OPEN' AND NOT hidden AND expiresAt > CURRENT_TIMESTAMP
+	) a
+	JOIN auction_alerts al ON al.itemType = a.itemType AND al.priceType = a.priceType AND al.maxPrice >= a.price
+		AND a.itemData @> al.itemMatch AND al.robloxId <> a.robloxId
+	WHERE NOT EXISTS (SELECT 1 FROM mailbox_outbox WHERE idempotencyKey = 'alert:' || al.id || ':auction:' || a.id)
+	`
+
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		fmt.Println("Failed to notify alerts:", err)
+		return
+	}
+
+	type match struct {
+		alert   *models.AuctionAlert
+		uid     int64
+		listing *models.AuctionAccount
+	}
+
+	var matches []match
+	for rows.Next() {
+		m := match{alert: &models.AuctionAlert{}, listing: &models.AuctionAccount{}}
+		if err := rows.Scan(&m.alert.ID, &m.alert.RobloxID, &m.alert.RobloxName, &m.uid, &m.listing.ItemType, &m.listing.ItemData, &m.listing.PriceType, &m.listing.Price); err != nil {
+			rows.Close()
+			fmt.Println("Failed to notify alerts:", err)
+			return
+		}
+
+		matches = append(matches, m)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		fmt.Println("Failed to notify alerts:", err)
+		return
+	}
+
+	for _, m := range matches {
+		if err := queueAlertNotice(ctx, tx, m.alert, m.uid, m.listing); err != nil {
+			fmt.Println("Failed to notify alerts:", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		fmt.Println("Failed to notify alerts:", err)
+	}
+}
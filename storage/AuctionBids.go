@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+var bidRefundMessages = map[string]string{
+	"OUTBID":    "You have been outbid. Your bid has been returned to your mailbox.",
+	"CANCELLED": "The auction you bid on has closed. Your bid has been returned to your mailbox.",
+}
+
+// PlaceBid takes the bid out of the bidder's wallet and holds it in escrow.
+// The bid it replaces is refunded to that bidder's wallet, not their mailbox,
+// and is returned as Outbid so the game server can tell them.
+func (s *PostgresStore) PlaceBid(item *models.AuctionAccount) (*models.AuctionBid, error) {
+	if item.UID == 0 {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	if item.ID == 0 || item.Name == "" {
+		return nil, fmt.Errorf("robloxId and robloxName cannot be empty")
+	}
+
+	if item.BidAmount <= 0 {
+		return nil, fmt.Errorf("bidAmount must be greater than 0")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkBan(ctx, tx, item.ID, "BUY"); err != nil {
+		return nil, err
+	}
+
+	checkQuery := `
+	SELECT robloxId, startPrice, priceType, highestBid, highestBidderId FROM auctions
+	WHERE id = $1 AND status = 'OPEN' AND NOT hidden AND mode <> 'DUTCH' AND expiresAt > CURRENT_TIMESTAMP AND (reservedBy = 0 OR reservedUntil <= CURRENT_TIMESTAMP)
+	FOR UPDATE
+	`
+
+	var sellerId, startPrice, highestBid, highestBidderId int64
+	var priceType string
+	err = tx.QueryRow(ctx, checkQuery, item.UID).Scan(&sellerId, &startPrice, &priceType, &highestBid, &highestBidderId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("auction is not open for bidding")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	if sellerId == item.ID {
+		return nil, fmt.Errorf("cannot bid on your own auction")
+	}
+
+	if highestBidderId == item.ID {
+		return nil, fmt.Errorf("you are already the highest bidder")
+	}
+
+	minimum := s.minimumBid(startPrice, highestBid)
+	if item.BidAmount < minimum {
+		return nil, fmt.Errorf("bid must be at least %d %s", minimum, priceType)
+	}
+
+	outbid, err := cancelActiveBid(ctx, tx, item.UID, "OUTBID")
+	if err != nil {
+		return nil, err
+	}
+
+	// The bid is held in escrow until it is outbid, cancelled or wins.
+	if _, err := adjustWallet(ctx, tx, item.ID, priceType, -item.BidAmount, "AUCTION_BID", item.UID, ""); err != nil {
+		return nil, err
+	}
+
+	if _, err := adjustWallet(ctx, tx, escrowAccount, priceType, item.BidAmount, "ESCROW_HOLD", item.UID, ""); err != nil {
+		return nil, err
+	}
+
+	bid := &models.AuctionBid{
+		AuctionID:  item.UID,
+		RobloxID:   item.ID,
+		RobloxName: item.Name,
+		Amount:     item.BidAmount,
+		PriceType:  priceType,
+		Status:     "ACTIVE",
+		Outbid:     outbid,
+	}
+
+	insertQuery := `
+	INSERT INTO auction_bids (auctionId, robloxId, robloxName, amount, priceType, escrowed)
+	VALUES ($1, $2, $3, $4, $5, true)
+	RETURNING id, placed
+	`
+
+	if err := tx.QueryRow(ctx, insertQuery, bid.AuctionID, bid.RobloxID, bid.RobloxName, bid.Amount, bid.PriceType).Scan(&bid.ID, &bid.Placed); err != nil {
+		return nil, fmt.Errorf("unable to insert row: %w", err)
+	}
+
+	updateQuery := `UPDATE auctions SET highestBid = $2, highestBidderId = $3, highestBidderName = $4 WHERE id = $1`
+	if _, err := tx.Exec(ctx, updateQuery, item.UID, bid.Amount, bid.RobloxID, bid.RobloxName); err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return bid, nil
+}
+
+// minimumBid is the start price for the first bid, and the current bid plus
+// the configured increment (never less than 1) after that.
+func (s *PostgresStore) minimumBid(startPrice int64, highestBid int64) int64 {
+	if highestBid == 0 {
+		return startPrice
+	}
+
+	increment := highestBid * s.cfg.BidIncrementPercent / 100
+	if increment < 1 {
+		increment = 1
+	}
+
+	return highestBid + increment
+}
+
+// cancelActiveBid moves the standing bid on a listing to status and refunds
+// it in the caller's transaction. Escrowed bids go back to the bidder's
+// wallet; older bids are refunded through the mailbox.
+func cancelActiveBid(ctx context.Context, tx pgx.Tx, auctionId int64, status string) (*models.AuctionBid, error) {
+	query := `
+	UPDATE auction_bids SET status = $2
+	WHERE auctionId = $1 AND status = 'ACTIVE'
+	RETURNING id, auctionId, robloxId, robloxName, amount, priceType, status, placed, escrowed
+	`
+
+	bid := &models.AuctionBid{}
+	var escrowed bool
+	err := tx.QueryRow(ctx, query, auctionId, status).Scan(&bid.ID, &bid.AuctionID, &bid.RobloxID, &bid.RobloxName, &bid.Amount, &bid.PriceType, &bid.Status, &bid.Placed, &escrowed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	if escrowed {
+		if _, err := adjustWallet(ctx, tx, escrowAccount, bid.PriceType, -bid.Amount, "ESCROW_RELEASE", auctionId, ""); err != nil {
+			return nil, err
+		}
+
+		if _, err := adjustWallet(ctx, tx, bid.RobloxID, bid.PriceType, bid.Amount, "BID_REFUND", auctionId, ""); err != nil {
+			return nil, err
+		}
+
+		bid.RefundedTo = "WALLET"
+		return bid, nil
+	}
+
+	bid.RefundedTo = "MAILBOX"
+	mail, err := newMailbox(bid.RobloxID, bid.RobloxName, bidRefundMessages[bid.Status], currencyItem(bid.PriceType, bid.Amount))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enqueueMailbox(ctx, tx, fmt.Sprintf("bid:%d:refund", bid.ID), mail); err != nil {
+		return nil, err
+	}
+
+	return bid, nil
+}
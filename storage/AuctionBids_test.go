@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/kattah7/v3/models"
+)
+
+func TestMinimumBid(t *testing.T) {
+	tests := []struct {
+		percent    int64
+		startPrice int64
+		highestBid int64
+		want       int64
+	}{
+		{5, 100, 0, 100},
+		{5, 100, 100, 105},
+		{5, 100, 10, 11},
+		{0, 100, 200, 201},
+		{10, 100, 1000, 1100},
+	}
+
+	for _, tt := range tests {
+		s := &PostgresStore{cfg: &models.Config{BidIncrementPercent: tt.percent}}
+		if got := s.minimumBid(tt.startPrice, tt.highestBid); got != tt.want {
+			t.Errorf("minimumBid(%d, %d) at %d%% = %d, want %d", tt.startPrice, tt.highestBid, tt.percent, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kattah7/v3/models"
+)
+
+const defaultAuctionPageSize = 50
+
+// listingPriceExpr is what a listing costs right now: the current price, or
+// the standing bid once bidding has passed it. Price sorts and filters use it
+// so Dutch listings move as their price drops and bid-on listings as bids rise.
+const listingPriceExpr = `GREATEST(` + currentPriceExpr + `, highestBid)`
+
+// listingPriceAt is listingPriceExpr evaluated at the timestamp expression at
+// instead of now.
+func listingPriceAt(at string) string {
+	return strings.Replace(listingPriceExpr, "CURRENT_TIMESTAMP", at, 1)
+}
+
+var auctionSorts = map[string]string{
+	"newest":     "id DESC",
+	"oldest":     "id ASC",
+	"price_asc":  listingPriceExpr + " ASC, id ASC",
+	"price_desc": listingPriceExpr + " DESC, id DESC",
+}
+
+// auctionCursor is the last listing of a page. Price sorts also carry the
+// time the first page was read, so every page compares prices as of then.
+type auctionCursor struct {
+	Price int64      `json:"p"`
+	UID   int64      `json:"i"`
+	At    *time.Time `json:"t,omitempty"`
+}
+
+// auctionFilter collects WHERE conditions, numbering each ? placeholder as
+// its argument is added.
+type auctionFilter struct {
+	where []string
+	args  []any
+}
+
+func (f *auctionFilter) add(cond string, args ...any) {
+	for _, arg := range args {
+		f.args = append(f.args, arg)
+		cond = strings.Replace(cond, "?", fmt.Sprintf("$%d", len(f.args)), 1)
+	}
+
+	f.where = append(f.where, cond)
+}
+
+// arg adds an argument used outside the WHERE clause and returns its
+// placeholder.
+func (f *auctionFilter) arg(value any) string {
+	f.args = append(f.args, value)
+	return fmt.Sprintf("$%d", len(f.args))
+}
+
+func (f *auctionFilter) String() string {
+	return strings.Join(f.where, " AND ")
+}
+
+// buildAuctionFilter turns the READ request into conditions on OPEN listings.
+// exclude drops one dimension so its facet counts ignore its own filter.
+func buildAuctionFilter(item *models.AuctionAccount, exclude string) *auctionFilter {
+	f := &auctionFilter{}
+	f.add("status = 'OPEN' AND NOT hidden")
+
+	if item.ItemType != "" && exclude != "itemType" {
+		f.add("itemType = ?", item.ItemType)
+	}
+
+	if item.PriceType != "" && exclude != "priceType" {
+		f.add("priceType = ?", item.PriceType)
+	}
+
+	if item.MinPrice > 0 {
+		f.add(listingPriceExpr+" >= ?", item.MinPrice)
+	}
+
+	if item.MaxPrice > 0 {
+		f.add(listingPriceExpr+" <= ?", item.MaxPrice)
+	}
+
+	if len(item.ItemData) > 0 && string(item.ItemData) != "null" {
+		f.add("itemData @> ?::jsonb", string(item.ItemData))
+	}
+
+	return f
+}
+
+func (s *PostgresStore) GetAuctions(item *models.AuctionAccount) (*models.AuctionPage, error) {
+	sort := item.Sort
+	if sort == "" {
+		sort = "newest"
+	}
+
+	orderBy, ok := auctionSorts[sort]
+	if !ok {
+		return nil, fmt.Errorf("sort must be newest, oldest, price_asc, or price_desc")
+	}
+
+	if len(item.ItemData) > 0 && string(item.ItemData) != "null" {
+		var itemData map[string]interface{}
+		if err := json.Unmarshal(item.ItemData, &itemData); err != nil {
+			return nil, fmt.Errorf("itemData filter must be a JSON object")
+		}
+	}
+
+	limit := item.Limit
+	if limit <= 0 {
+		limit = defaultAuctionPageSize
+	}
+	if limit > LIMIT {
+		limit = LIMIT
+	}
+
+	f := buildAuctionFilter(item, "")
+
+	var cursor *auctionCursor
+	if item.Cursor != "" {
+		decoded, err := decodeAuctionCursor(item.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		cursor = decoded
+	}
+
+	// A Dutch listing's price keeps dropping between page loads. Price sorts
+	// are evaluated at the time the first page was read so such a listing is
+	// neither skipped nor shown twice.
+	var snapshot *time.Time
+	priceExpr := listingPriceExpr
+	if sort == "price_asc" || sort == "price_desc" {
+		if cursor != nil && cursor.At != nil {
+			snapshot = cursor.At
+		} else {
+			now, err := s.browseSnapshot()
+			if err != nil {
+				return nil, err
+			}
+
+			snapshot = &now
+		}
+
+		priceExpr = listingPriceAt(f.arg(*snapshot) + "::timestamp")
+		orderBy = strings.Replace(orderBy, listingPriceExpr, priceExpr, 1)
+	}
+
+	if cursor != nil {
+		switch sort {
+		case "newest":
+			f.add("id < ?", cursor.UID)
+		case "oldest":
+			f.add("id > ?", cursor.UID)
+		case "price_asc":
+			f.add("("+priceExpr+", id) > (?, ?)", cursor.Price, cursor.UID)
+		case "price_desc":
+			f.add("("+priceExpr+", id) < (?, ?)", cursor.Price, cursor.UID)
+		}
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM auctions WHERE %s ORDER BY %s LIMIT %d`, auctionColumns, f, orderBy, limit+1)
+
+	listings, err := s.queryAuctions(query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.AuctionPage{Listings: listings}
+
+	if len(listings) > limit {
+		page.Listings = listings[:limit]
+		next := &auctionCursor{UID: page.Listings[limit-1].UID}
+
+		if snapshot != nil {
+			query := `SELECT ` + listingPriceAt("$2::timestamp") + ` FROM auctions WHERE id = $1`
+			if err := s.db.QueryRow(context.Background(), query, next.UID, *snapshot).Scan(&next.Price); err != nil {
+				return nil, fmt.Errorf("Unable to query row: %w", err)
+			}
+
+			next.At = snapshot
+		}
+
+		page.NextCursor = encodeAuctionCursor(next)
+	}
+
+	if err := s.attachPriceHistory(page.Listings); err != nil {
+		return nil, err
+	}
+
+	if page.Facets.ItemTypes, err = s.auctionFacet(item, "itemType"); err != nil {
+		return nil, err
+	}
+
+	if page.Facets.PriceTypes, err = s.auctionFacet(item, "priceType"); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// auctionFacet counts matching listings per value of column.
+func (s *PostgresStore) auctionFacet(item *models.AuctionAccount, column string) (map[string]int64, error) {
+	f := buildAuctionFilter(item, column)
+	query := fmt.Sprintf(`SELECT %s, COUNT(*) FROM auctions WHERE %s GROUP BY %s`, column, f, column)
+
+	rows, err := s.db.Query(context.Background(), query, f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+
+	for rows.Next() {
+		var value string
+		var count int64
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		counts[value] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// browseSnapshot reads the database clock in the same form as the listed
+// column, for comparing prices across pages.
+func (s *PostgresStore) browseSnapshot() (time.Time, error) {
+	var now time.Time
+	if err := s.db.QueryRow(context.Background(), `SELECT LOCALTIMESTAMP`).Scan(&now); err != nil {
+		return now, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	return now, nil
+}
+
+func encodeAuctionCursor(cursor *auctionCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeAuctionCursor(value string) (*auctionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	cursor := &auctionCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return cursor, nil
+}
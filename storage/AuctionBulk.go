@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+const maxBulkItems = 50
+
+func bulkResults(items []*models.AuctionAccount) ([]*models.AuctionBulkResult, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("items cannot be empty")
+	}
+
+	if len(items) > maxBulkItems {
+		return nil, fmt.Errorf("items cannot hold more than %d entries", maxBulkItems)
+	}
+
+	// A null entry is reported against its index; callers skip entries that
+	// already have an error.
+	results := make([]*models.AuctionBulkResult, len(items))
+	for i, item := range items {
+		results[i] = &models.AuctionBulkResult{Index: i}
+		if item == nil {
+			results[i].Error = "item cannot be null"
+		}
+	}
+
+	return results, nil
+}
+
+func bulkFailed(results []*models.AuctionBulkResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+
+	if failed == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%d of %d items failed, nothing was changed", failed, len(results))
+}
+
+// ListAuctionBulk lists every entry of items for the seller in robloxId and
+// robloxName, using the same rules as LIST. Either every listing is created
+// or none are; the per-item results say which entries were rejected.
+func (s *PostgresStore) ListAuctionBulk(item *models.AuctionAccount) ([]*models.AuctionBulkResult, error) {
+	if item.ID == 0 || item.Name == "" {
+		return nil, fmt.Errorf("robloxId and robloxName cannot be empty")
+	}
+
+	results, err := bulkResults(item.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	durations := make([]int64, len(item.Items))
+	for i, listing := range item.Items {
+		if listing == nil {
+			continue
+		}
+
+		listing.ID = item.ID
+		listing.Name = item.Name
+
+		duration, err := s.validateListing(listing)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		durations[i] = duration
+	}
+
+	if err := bulkFailed(results); err != nil {
+		return results, err
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.checkListingLimit(ctx, tx, item.ID, item.Tier, int64(len(item.Items))); err != nil {
+		return nil, err
+	}
+
+	for i, listing := range item.Items {
+		uid, err := s.insertListing(ctx, tx, listing, durations[i])
+		if err != nil {
+			return nil, err
+		}
+
+		results[i].UID = uid
+		results[i].Success = true
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return results, nil
+}
+
+// UnlistAuctionBulk unlists every listing id in items for the seller in
+// robloxId, using the same rules as AUCTION_UNLIST. Either every listing is
+// removed or none are.
+func (s *PostgresStore) UnlistAuctionBulk(item *models.AuctionAccount) ([]*models.AuctionBulkResult, error) {
+	if item.ID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	results, err := bulkResults(item.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	checkQuery := `
+	SELECT robloxId, reservedBy <> 0 AND reservedUntil > CURRENT_TIMESTAMP, hidden FROM auctions
+	WHERE id = $1 AND status = 'OPEN'
+	FOR UPDATE
+	`
+
+	seen := make(map[int64]bool)
+	for i, listing := range item.Items {
+		if listing == nil {
+			continue
+		}
+
+		results[i].UID = listing.UID
+
+		if listing.UID == 0 {
+			results[i].Error = "id cannot be empty"
+			continue
+		}
+
+		if seen[listing.UID] {
+			results[i].Error = "id is listed more than once"
+			continue
+		}
+		seen[listing.UID] = true
+
+		var robloxId int64
+		var reserved, hidden bool
+		err := tx.QueryRow(ctx, checkQuery, listing.UID).Scan(&robloxId, &reserved, &hidden)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			results[i].Error = "auction is not open"
+		case err != nil:
+			return nil, fmt.Errorf("Unable to query row: %w", err)
+		case robloxId != item.ID:
+			results[i].Error = "robloxId does not match with id"
+		case reserved:
+			results[i].Error = "auction is reserved by a buyer"
+		case hidden:
+			results[i].Error = "auction is under review"
+		}
+	}
+
+	if err := bulkFailed(results); err != nil {
+		return results, err
+	}
+
+	for i, listing := range item.Items {
+		if err := closeAuctionTx(ctx, tx, listing.UID, unlistQuery, "UNLISTED", item.ID, item.Name, ""); err != nil {
+			return nil, err
+		}
+
+		results[i].Success = true
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return results, nil
+}
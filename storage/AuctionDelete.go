@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+// Deleted listings are kept with status DELETED and the status they had
+// before, so an admin can put them back with RestoreAuction. Sold listings
+// can't be deleted: they hold escrow and owe the buyer an item. A standing
+// bid is refunded by the delete, so it is cleared from the listing too.
+const (
+	ownerDeleteQuery = `
+	UPDATE auctions SET previousStatus = status, status = 'DELETED', deletedAt = CURRENT_TIMESTAMP,
+		highestBid = 0, highestBidderId = 0, highestBidderName = ''
+	WHERE id = $1 AND status = 'OPEN' AND NOT hidden AND (reservedBy = 0 OR reservedUntil <= CURRENT_TIMESTAMP)
+	`
+	adminDeleteQuery = `
+	UPDATE auctions SET previousStatus = status, status = 'DELETED', deletedAt = CURRENT_TIMESTAMP, reservedBy = 0, reservedUntil = NULL,
+		highestBid = 0, highestBidderId = 0, highestBidderName = ''
+	WHERE id = $1 AND status IN ('OPEN', 'EXPIRED')
+	`
+)
+
+// RemoveAuction deletes one of the seller's own OPEN listings. It follows the
+// same rules as AuctionUnlist; staff delete other listings through
+// ModerateDeleteAuction.
+func (s *PostgresStore) RemoveAuction(item *models.AuctionAccount) error {
+	if item.UID == 0 {
+		return fmt.Errorf("uid cannot be empty")
+	}
+
+	if item.ID == 0 {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	checkQuery := `SELECT robloxId FROM auctions WHERE id = $1 AND status = 'OPEN'`
+
+	var robloxId int64
+	err := s.db.QueryRow(context.Background(), checkQuery, item.UID).Scan(&robloxId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("auction is not open")
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	if robloxId != item.ID {
+		return fmt.Errorf("robloxId does not match with id")
+	}
+
+	return s.closeAuction(item.UID, ownerDeleteQuery, "DELETED", item.ID, item.Name)
+}
+
+// ModerateDeleteAuction deletes an OPEN or EXPIRED listing. Nothing is mailed
+// back; the listing can be restored as it was.
+func (s *PostgresStore) ModerateDeleteAuction(req *models.ModerationRequest) error {
+	if err := checkModeration(req); err != nil {
+		return err
+	}
+
+	if req.ID == 0 {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var sellerId int64
+	err = tx.QueryRow(ctx, `SELECT robloxId FROM auctions WHERE id = $1 AND status IN ('OPEN', 'EXPIRED') FOR UPDATE`, req.ID).Scan(&sellerId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("no OPEN or EXPIRED listing with id %d to delete", req.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	if err := closeAuctionTx(ctx, tx, req.ID, adminDeleteQuery, "DELETED_BY_ADMIN", 0, req.Moderator, req.Reason); err != nil {
+		return err
+	}
+
+	if err := recordModeration(ctx, tx, "DELETE", sellerId, req.ID, req); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreAuction puts a listing deleted by an admin back in the status it had
+// when it was deleted. Bids and offers cancelled by the delete are not
+// restored, and an OPEN listing past its expiry is picked up by the next
+// expiry run. A seller's own delete can't be restored: the game server gives
+// the item back, so restoring the listing would duplicate it.
+func (s *PostgresStore) RestoreAuction(req *models.ModerationRequest) (*models.AuctionAccount, error) {
+	if err := checkModeration(req); err != nil {
+		return nil, err
+	}
+
+	if req.ID == 0 {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var deleteEvent string
+	eventQuery := `
+	SELECT event FROM auction_events
+	WHERE auctionId = $1 AND event IN ('DELETED', 'DELETED_BY_ADMIN')
+	ORDER BY id DESC LIMIT 1
+	`
+	err = tx.QueryRow(ctx, eventQuery, req.ID).Scan(&deleteEvent)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	if deleteEvent == "DELETED" {
+		return nil, fmt.Errorf("listing %d was deleted by its seller and cannot be restored", req.ID)
+	}
+
+	query := `
+	UPDATE auctions SET status = previousStatus, previousStatus = '', deletedAt = NULL
+	WHERE id = $1 AND status = 'DELETED' AND previousStatus <> ''
+	RETURNING ` + auctionColumns
+
+	restored := &models.AuctionAccount{}
+	err = scanAuction(tx.QueryRow(ctx, query, req.ID), restored)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("no DELETED listing with id %d", req.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	if err := recordAuctionEvent(ctx, tx, req.ID, "RESTORED", 0, req.Moderator, req.Reason); err != nil {
+		return nil, err
+	}
+
+	if err := recordModeration(ctx, tx, "RESTORE", restored.ID, req.ID, req); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return restored, nil
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+// insertTestAuction creates an OPEN listing directly, bypassing LIST checks.
+func insertTestAuction(t *testing.T, s *PostgresStore, sellerId int64, price int64) int64 {
+	t.Helper()
+
+	query := `
+	INSERT INTO auctions (robloxId, robloxName, itemType, itemData, startPrice, priceType, expiresAt)
+	VALUES ($1, 'seller', 'Pet', '{"name": "Dog"}', $2, 'Coins', CURRENT_TIMESTAMP + INTERVAL '1 hour')
+	RETURNING id
+	`
+
+	var uid int64
+	if err := s.db.QueryRow(context.Background(), query, sellerId, price).Scan(&uid); err != nil {
+		t.Fatalf("insert auction: %v", err)
+	}
+
+	return uid
+}
+
+func walletBalance(t *testing.T, s *PostgresStore, robloxId int64, priceType string) int64 {
+	t.Helper()
+
+	var balance int64
+	inTx(t, s, func(ctx context.Context, tx pgx.Tx) error {
+		wallet, err := getWalletBalance(ctx, tx, robloxId, priceType)
+		if err != nil {
+			return err
+		}
+
+		balance = wallet.Balance
+		return nil
+	})
+
+	return balance
+}
+
+func TestDeleteRestoreExpireDropsRefundedBid(t *testing.T) {
+	s := newTestStore(t)
+
+	const seller, bidder = 10, 20
+	uid := insertTestAuction(t, s, seller, 50)
+
+	inTx(t, s, func(ctx context.Context, tx pgx.Tx) error {
+		_, err := adjustWallet(ctx, tx, bidder, "Coins", 100, "DEPOSIT", 0, "")
+		return err
+	})
+
+	if _, err := s.PlaceBid(&models.AuctionAccount{UID: uid, ID: bidder, Name: "bidder", BidAmount: 60}); err != nil {
+		t.Fatalf("PlaceBid: %v", err)
+	}
+
+	req := &models.ModerationRequest{ID: uid, Reason: "test", Moderator: "admin"}
+	if err := s.ModerateDeleteAuction(req); err != nil {
+		t.Fatalf("ModerateDeleteAuction: %v", err)
+	}
+
+	if got := walletBalance(t, s, bidder, "Coins"); got != 100 {
+		t.Fatalf("bidder balance after delete = %d, want 100", got)
+	}
+
+	restored, err := s.RestoreAuction(req)
+	if err != nil {
+		t.Fatalf("RestoreAuction: %v", err)
+	}
+
+	if restored.HighestBidderID != 0 || restored.HighestBid != 0 {
+		t.Fatalf("restored listing kept bid %d from %d", restored.HighestBid, restored.HighestBidderID)
+	}
+
+	inTx(t, s, func(ctx context.Context, tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `UPDATE auctions SET expiresAt = CURRENT_TIMESTAMP - INTERVAL '1 second' WHERE id = $1`, uid)
+		return err
+	})
+
+	if err := s.expireAuction(uid); err != nil {
+		t.Fatalf("expireAuction: %v", err)
+	}
+
+	var status string
+	var buyerId int64
+	err = s.db.QueryRow(context.Background(), `SELECT status, buyerId FROM auctions WHERE id = $1`, uid).Scan(&status, &buyerId)
+	if err != nil {
+		t.Fatalf("select auction: %v", err)
+	}
+
+	if status != "EXPIRED" || buyerId != 0 {
+		t.Fatalf("after expiry status = %s, buyerId = %d; want EXPIRED with no buyer", status, buyerId)
+	}
+}
+
+func TestRestoreRejectsOwnerDelete(t *testing.T) {
+	s := newTestStore(t)
+
+	const seller = 10
+	uid := insertTestAuction(t, s, seller, 50)
+
+	if err := s.RemoveAuction(&models.AuctionAccount{UID: uid, ID: seller, Name: "seller"}); err != nil {
+		t.Fatalf("RemoveAuction: %v", err)
+	}
+
+	req := &models.ModerationRequest{ID: uid, Reason: "test", Moderator: "admin"}
+	if _, err := s.RestoreAuction(req); err == nil {
+		t.Fatal("restored a listing deleted by its seller")
+	}
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+// EditAuction lowers the price of an OPEN listing in place, keeping its id,
+// age and slot. PURCHASE charges whatever the row holds, so a price can only
+// go down and the currency can't change; otherwise a seller could raise it
+// under a buyer who has already decided to buy. Only the seller can edit, and
+// not once bidding has started, while a buyer holds a reservation, or while
+// the listing is under review. Pending offers were made against the old
+// price, so they are cancelled.
+func (s *PostgresStore) EditAuction(item *models.AuctionAccount) (*models.AuctionAccount, error) {
+	if item.UID == 0 {
+		return nil, fmt.Errorf("uid cannot be empty")
+	}
+
+	if item.ID == 0 {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	if item.Price <= 0 {
+		return nil, fmt.Errorf("startPrice must be greater than 0")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkBan(ctx, tx, item.ID, "LIST"); err != nil {
+		return nil, err
+	}
+
+	checkQuery := `
+	SELECT robloxId, startPrice, priceType, mode, floorPrice, priceStep, stepSeconds, reservePrice, highestBidderId, hidden,
+		reservedBy <> 0 AND reservedUntil > CURRENT_TIMESTAMP
+	FROM auctions WHERE id = $1 AND status = 'OPEN' AND expiresAt > CURRENT_TIMESTAMP
+	FOR UPDATE
+	`
+
+	current := &models.AuctionAccount{}
+	var highestBidderId int64
+	var hidden, reserved bool
+
+	err = tx.QueryRow(ctx, checkQuery, item.UID).Scan(&current.ID, &current.Price, &current.PriceType, &current.Mode,
+		&current.FloorPrice, &current.PriceStep, &current.StepSeconds, &current.ReservePrice, &highestBidderId, &hidden, &reserved)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("auction is not open")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	if current.ID != item.ID {
+		return nil, fmt.Errorf("robloxId does not match with id")
+	}
+
+	if hidden {
+		return nil, fmt.Errorf("auction is under review")
+	}
+
+	if highestBidderId != 0 {
+		return nil, fmt.Errorf("cannot edit an auction that has bids")
+	}
+
+	if reserved {
+		return nil, fmt.Errorf("auction is reserved")
+	}
+
+	priceType := current.PriceType
+	if item.PriceType != "" && item.PriceType != current.PriceType {
+		return nil, fmt.Errorf("priceType cannot be changed")
+	}
+
+	if item.Price >= current.Price {
+		return nil, fmt.Errorf("startPrice must be lower than %d", current.Price)
+	}
+
+	// The new price has to fit the listing's mode: above a Dutch floor and
+	// below a hidden reserve.
+	check := &models.AuctionAccount{
+		Price:        item.Price,
+		Mode:         current.Mode,
+		FloorPrice:   current.FloorPrice,
+		PriceStep:    current.PriceStep,
+		StepSeconds:  current.StepSeconds,
+		ReservePrice: current.ReservePrice,
+	}
+	if err := validateAuctionMode(check); err != nil {
+		return nil, err
+	}
+
+	updateQuery := `UPDATE auctions SET startPrice = $2, priceType = $3 WHERE id = $1`
+	if _, err := tx.Exec(ctx, updateQuery, item.UID, item.Price, priceType); err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	historyQuery := `
+	INSERT INTO auction_price_history (auctionId, oldPrice, newPrice, oldPriceType, newPriceType)
+	VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := tx.Exec(ctx, historyQuery, item.UID, current.Price, item.Price, current.PriceType, priceType); err != nil {
+		return nil, fmt.Errorf("unable to insert row: %w", err)
+	}
+
+	detail := fmt.Sprintf("%d %s", current.Price, current.PriceType)
+	if err := recordAuctionEvent(ctx, tx, item.UID, "EDITED", item.ID, item.Name, detail); err != nil {
+		return nil, err
+	}
+
+	if err := cancelPendingOffers(ctx, tx, item.UID); err != nil {
+		return nil, err
+	}
+
+	edited := &models.AuctionAccount{}
+	if err := scanAuction(tx.QueryRow(ctx, `SELECT `+auctionColumns+` FROM auctions WHERE id = $1`, item.UID), edited); err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	// The cheaper listing can now match alerts it missed when it was listed.
+	// Alerts it already matched are not notified twice.
+	if err := notifyAuctionAlerts(ctx, tx, item.UID, edited); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	if err := s.attachPriceHistory([]*models.AuctionAccount{edited}); err != nil {
+		return nil, err
+	}
+
+	return edited, nil
+}
+
+// attachPriceHistory fills in PriceHistory, oldest first, on each listing.
+func (s *PostgresStore) attachPriceHistory(listings []*models.AuctionAccount) error {
+	if len(listings) == 0 {
+		return nil
+	}
+
+	byId := make(map[int64]*models.AuctionAccount, len(listings))
+	ids := make([]int64, 0, len(listings))
+	for _, listing := range listings {
+		byId[listing.UID] = listing
+		ids = append(ids, listing.UID)
+	}
+
+	query := `
+	SELECT id, auctionId, oldPrice, newPrice, oldPriceType, newPriceType, created
+	FROM auction_price_history WHERE auctionId = ANY($1)
+	ORDER BY id ASC
+	`
+
+	rows, err := s.db.Query(context.Background(), query, ids)
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		change := &models.AuctionPriceChange{}
+		err := rows.Scan(&change.ID, &change.AuctionID, &change.OldPrice, &change.NewPrice, &change.OldPriceType, &change.NewPriceType, &change.Created)
+		if err != nil {
+			return fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		if listing, ok := byId[change.AuctionID]; ok {
+			listing.PriceHistory = append(listing.PriceHistory, change)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+// systemActor is recorded as the actor for transitions made by scheduled jobs.
+const systemActor = "SYSTEM"
+
+// recordAuctionEvent appends a lifecycle transition for a listing, taking a
+// snapshot of the row as it is inside tx. Call it before any DELETE of the row.
+func recordAuctionEvent(ctx context.Context, tx pgx.Tx, auctionId int64, event string, actorId int64, actorName string, detail string) error {
+	query := `
+	INSERT INTO auction_events (auctionId, event, sellerId, actorId, actorName, itemType, itemData, priceType, price, detail)
+	SELECT id, $2, robloxId, $3, $4, itemType, itemData, priceType, CASE WHEN soldPrice > 0 THEN soldPrice ELSE startPrice END, $5
+	FROM auctions WHERE id = $1
+	`
+
+	result, err := tx.Exec(ctx, query, auctionId, event, actorId, actorName, detail)
+	if err != nil {
+		return fmt.Errorf("unable to insert auction event: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("auction %d does not exist", auctionId)
+	}
+
+	return nil
+}
+
+// GetAuctionEvents returns the history of a listing when id is set, otherwise
+// every event where robloxId was the seller or the actor.
+func (s *PostgresStore) GetAuctionEvents(item *models.AuctionAccount) ([]*models.AuctionEvent, error) {
+	if item.UID == 0 && item.ID == 0 {
+		return nil, fmt.Errorf("id or robloxId cannot be empty")
+	}
+
+	query := `
+	SELECT id, auctionId, event, sellerId, actorId, actorName, itemType, itemData, priceType, price, detail, created
+	FROM auction_events
+	WHERE ($1 <> 0 AND auctionId = $1) OR ($1 = 0 AND (sellerId = $2 OR actorId = $2))
+	ORDER BY id DESC
+	LIMIT $3
+	`
+
+	rows, err := s.db.Query(context.Background(), query, item.UID, item.ID, LIMIT)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	events := make([]*models.AuctionEvent, 0)
+
+	for rows.Next() {
+		event := &models.AuctionEvent{}
+		err := rows.Scan(&event.ID, &event.AuctionID, &event.Event, &event.SellerID, &event.ActorID, &event.ActorName,
+			&event.ItemType, &event.ItemData, &event.PriceType, &event.Price, &event.Detail, &event.Created)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+const (
+	defaultReportDays = 30
+	maxReportDays     = 365
+)
+
+// reportDays clamps the number of days an admin report covers.
+func reportDays(days int) int {
+	if days <= 0 {
+		return defaultReportDays
+	}
+
+	if days > maxReportDays {
+		return maxReportDays
+	}
+
+	return days
+}
+
+// saleTax is the configured percentage of price, rounded down.
+func (s *PostgresStore) saleTax(priceType string, price int64) int64 {
+	return price * s.cfg.AuctionFees[priceType].TaxPercent / 100
+}
+
+func recordFee(ctx context.Context, tx pgx.Tx, auctionId int64, robloxId int64, kind string, priceType string, amount int64) error {
+	query := `INSERT INTO auction_fees (auctionId, robloxId, kind, priceType, amount) VALUES ($1, $2, $3, $4, $5)`
+
+	if _, err := tx.Exec(ctx, query, auctionId, robloxId, kind, priceType, amount); err != nil {
+		return fmt.Errorf("unable to insert fee row: %w", err)
+	}
+
+	return nil
+}
+
+// chargeListingFee takes the flat listing fee for the listing's priceType from
+// the seller's wallet. The fee is not refunded if the listing does not sell.
+func (s *PostgresStore) chargeListingFee(ctx context.Context, tx pgx.Tx, uid int64, item *models.AuctionAccount) error {
+	fee := s.cfg.AuctionFees[item.PriceType].ListingFee
+	if fee <= 0 {
+		return nil
+	}
+
+	if _, err := adjustWallet(ctx, tx, item.ID, item.PriceType, -fee, "LISTING_FEE", uid, ""); err != nil {
+		return err
+	}
+
+	if _, err := adjustWallet(ctx, tx, houseAccount, item.PriceType, fee, "LISTING_FEE", uid, ""); err != nil {
+		return err
+	}
+
+	return recordFee(ctx, tx, uid, item.ID, "LISTING_FEE", item.PriceType, fee)
+}
+
+// collectSaleTax takes the sales tax out of a claimed sale and sets Tax and
+// NetProceeds on claimed. Escrow is released with the tax going to the house.
+// When toWallet is set the seller is paid the net amount into their wallet;
+// otherwise the caller pays it out through the mailbox. For sales without
+// escrow, won by bids placed before bids were escrowed, the game server pays
+// out NetProceeds itself.
+func (s *PostgresStore) collectSaleTax(ctx context.Context, tx pgx.Tx, claimed *models.AuctionAccount, toWallet bool) error {
+	tax := s.saleTax(claimed.PriceType, claimed.SoldPrice)
+	if claimed.Escrowed > 0 && tax > claimed.Escrowed {
+		tax = claimed.Escrowed
+	}
+
+	claimed.Tax = tax
+	claimed.NetProceeds = claimed.SoldPrice - tax
+
+	if tax > 0 {
+		if _, err := tx.Exec(ctx, `UPDATE auctions SET tax = $2 WHERE id = $1`, claimed.UID, tax); err != nil {
+			return fmt.Errorf("Unable to update row: %w", err)
+		}
+
+		if err := recordFee(ctx, tx, claimed.UID, claimed.ID, "SALES_TAX", claimed.PriceType, tax); err != nil {
+			return err
+		}
+	}
+
+	if claimed.Escrowed <= 0 {
+		return nil
+	}
+
+	reason := "ESCROW_RELEASE"
+	if !toWallet {
+		reason = "PROCEEDS_MAILED"
+	}
+
+	if _, err := adjustWallet(ctx, tx, escrowAccount, claimed.PriceType, -claimed.Escrowed, reason, claimed.UID, ""); err != nil {
+		return err
+	}
+
+	if toWallet {
+		if _, err := adjustWallet(ctx, tx, claimed.ID, claimed.PriceType, claimed.Escrowed-tax, "AUCTION_SALE", claimed.UID, ""); err != nil {
+			return err
+		}
+	}
+
+	if tax > 0 {
+		if _, err := adjustWallet(ctx, tx, houseAccount, claimed.PriceType, tax, "SALES_TAX", claimed.UID, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetFeeReport totals the taxes and fees taken per day, priceType and kind.
+func (s *PostgresStore) GetFeeReport(req *models.AuctionFeeReport) ([]*models.AuctionFeeDay, error) {
+	query := `
+	SELECT date_trunc('day', created), priceType, kind, SUM(amount), COUNT(*)
+	FROM auction_fees
+	WHERE created > date_trunc('day', CURRENT_TIMESTAMP) - make_interval(days => $1) AND ($2 = '' OR priceType = $2)
+	GROUP BY 1, 2, 3
+	ORDER BY 1 DESC, 2, 3
+	`
+
+	rows, err := s.db.Query(context.Background(), query, reportDays(req.Days)-1, req.PriceType)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	report := make([]*models.AuctionFeeDay, 0)
+
+	for rows.Next() {
+		day := &models.AuctionFeeDay{}
+		if err := rows.Scan(&day.Day, &day.PriceType, &day.Kind, &day.Amount, &day.Count); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		report = append(report, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return report, nil
+}
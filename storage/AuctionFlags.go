@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+const flagColumns = `id, kind, COALESCE(itemUuid, ''), COALESCE(auctionId, 0), COALESCE(robloxId, 0), evidence, status, note, reviewer, created, reviewed`
+
+func scanFlag(row pgx.Row, flag *models.AuctionFlag) error {
+	return row.Scan(&flag.ID, &flag.Kind, &flag.ItemUUID, &flag.AuctionID, &flag.RobloxID, &flag.Evidence, &flag.Status, &flag.Note, &flag.Reviewer, &flag.Created, &flag.Reviewed)
+}
+
+// raiseFlag queues a suspicious event for admin review. fingerprint
+// identifies the finding, so raising the same flag twice is a no-op.
+func raiseFlag(ctx context.Context, tx pgx.Tx, fingerprint string, kind string, itemUuid string, auctionId int64, robloxId int64, evidence interface{}) error {
+	data, err := json.Marshal(evidence)
+	if err != nil {
+		return fmt.Errorf("unable to marshal evidence: %w", err)
+	}
+
+	query := `
+	INSERT INTO auction_flags (fingerprint, kind, itemUuid, auctionId, robloxId, evidence)
+	VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, 0), NULLIF($5, 0), $6)
+	ON CONFLICT (fingerprint) DO NOTHING
+	`
+
+	if _, err := tx.Exec(ctx, query, fingerprint, kind, itemUuid, auctionId, robloxId, data); err != nil {
+		return fmt.Errorf("unable to insert flag: %w", err)
+	}
+
+	return nil
+}
+
+// refreshFlag raises a flag like raiseFlag, but replaces the evidence of an
+// existing flag with the same fingerprint instead of ignoring it. It is used
+// for findings that keep growing, such as a pair's sale count.
+func refreshFlag(ctx context.Context, tx pgx.Tx, fingerprint string, kind string, itemUuid string, auctionId int64, robloxId int64, evidence interface{}) error {
+	data, err := json.Marshal(evidence)
+	if err != nil {
+		return fmt.Errorf("unable to marshal evidence: %w", err)
+	}
+
+	query := `
+	INSERT INTO auction_flags (fingerprint, kind, itemUuid, auctionId, robloxId, evidence)
+	VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, 0), NULLIF($5, 0), $6)
+	ON CONFLICT (fingerprint) DO UPDATE SET evidence = EXCLUDED.evidence
+	`
+
+	if _, err := tx.Exec(ctx, query, fingerprint, kind, itemUuid, auctionId, robloxId, data); err != nil {
+		return fmt.Errorf("unable to insert flag: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuctionFlags lists flags by status (OPEN by default) and optionally kind.
+func (s *PostgresStore) GetAuctionFlags(req *models.FlagRequest) ([]*models.AuctionFlag, error) {
+	status := req.Status
+	if status == "" {
+		status = "OPEN"
+	}
+
+	query := `SELECT ` + flagColumns + ` FROM auction_flags WHERE status = $1 AND ($2 = '' OR kind = $2) ORDER BY id DESC LIMIT $3`
+
+	rows, err := s.db.Query(context.Background(), query, status, req.Kind, LIMIT)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	flags := make([]*models.AuctionFlag, 0)
+
+	for rows.Next() {
+		flag := &models.AuctionFlag{}
+		if err := scanFlag(rows, flag); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		flags = append(flags, flag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return flags, nil
+}
+
+// ReviewAuctionFlag closes an OPEN flag as CONFIRMED or DISMISSED.
+func (s *PostgresStore) ReviewAuctionFlag(req *models.FlagRequest) (*models.AuctionFlag, error) {
+	if req.ID == 0 {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	if req.Status != "CONFIRMED" && req.Status != "DISMISSED" {
+		return nil, fmt.Errorf("status must be CONFIRMED or DISMISSED")
+	}
+
+	if req.Reviewer == "" {
+		return nil, fmt.Errorf("reviewer cannot be empty")
+	}
+
+	query := `
+	UPDATE auction_flags SET status = $2, note = $3, reviewer = $4, reviewed = CURRENT_TIMESTAMP
+	WHERE id = $1 AND status = 'OPEN'
+	RETURNING ` + flagColumns
+
+	flag := &models.AuctionFlag{}
+	err := scanFlag(s.db.QueryRow(context.Background(), query, req.ID, req.Status, req.Note, req.Reviewer), flag)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("no open flag with id %d", req.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	return flag, nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/kattah7/v3/models"
+)
+
+const minDutchStepSeconds = 60
+
+// currentPriceExpr is the price a listing sells for right now. A DUTCH
+// listing drops by priceStep every stepSeconds after it was listed, down to
+// floorPrice; every other listing sells at startPrice.
+const currentPriceExpr = `(CASE WHEN mode = 'DUTCH' AND stepSeconds > 0
+	THEN GREATEST(floorPrice, startPrice - priceStep * FLOOR(EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - listed)) / stepSeconds)::BIGINT)
+	ELSE startPrice END)`
+
+// reserveMetExpr tells bidders whether a RESERVE listing's hidden minimum has
+// been reached without revealing it. It is NULL for other modes.
+const reserveMetExpr = `(CASE WHEN mode = 'RESERVE' THEN highestBid >= reservePrice END)`
+
+// validateAuctionMode checks the mode-specific LIST fields. FIXED is the
+// default. A RESERVE listing is sold only by bid, with startPrice as the
+// opening bid and reservePrice as the hidden minimum.
+func validateAuctionMode(item *models.AuctionAccount) error {
+	if item.Mode == "" {
+		item.Mode = "FIXED"
+	}
+
+	switch item.Mode {
+	case "FIXED":
+		item.FloorPrice, item.PriceStep, item.StepSeconds, item.ReservePrice = 0, 0, 0, 0
+	case "DUTCH":
+		if item.FloorPrice <= 0 || item.FloorPrice >= item.Price {
+			return fmt.Errorf("floorPrice must be greater than 0 and less than startPrice")
+		}
+
+		if item.PriceStep <= 0 {
+			return fmt.Errorf("priceStep must be greater than 0")
+		}
+
+		if item.StepSeconds < minDutchStepSeconds {
+			return fmt.Errorf("stepSeconds must be at least %d", minDutchStepSeconds)
+		}
+
+		item.ReservePrice = 0
+	case "RESERVE":
+		if item.ReservePrice <= item.Price {
+			return fmt.Errorf("reservePrice must be greater than startPrice")
+		}
+
+		item.FloorPrice, item.PriceStep, item.StepSeconds = 0, 0, 0
+	default:
+		return fmt.Errorf("mode must be FIXED, DUTCH, or RESERVE")
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/kattah7/v3/models"
+)
+
+func TestValidateAuctionMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    models.AuctionAccount
+		wantErr bool
+	}{
+		{"default fixed", models.AuctionAccount{Price: 100}, false},
+		{"fixed", models.AuctionAccount{Mode: "FIXED", Price: 100, FloorPrice: 10}, false},
+		{"dutch", models.AuctionAccount{Mode: "DUTCH", Price: 100, FloorPrice: 50, PriceStep: 5, StepSeconds: 60}, false},
+		{"dutch without floor", models.AuctionAccount{Mode: "DUTCH", Price: 100, PriceStep: 5, StepSeconds: 60}, true},
+		{"dutch floor at start", models.AuctionAccount{Mode: "DUTCH", Price: 100, FloorPrice: 100, PriceStep: 5, StepSeconds: 60}, true},
+		{"dutch without step", models.AuctionAccount{Mode: "DUTCH", Price: 100, FloorPrice: 50, StepSeconds: 60}, true},
+		{"dutch step too fast", models.AuctionAccount{Mode: "DUTCH", Price: 100, FloorPrice: 50, PriceStep: 5, StepSeconds: 59}, true},
+		{"reserve", models.AuctionAccount{Mode: "RESERVE", Price: 100, ReservePrice: 500}, false},
+		{"reserve at start", models.AuctionAccount{Mode: "RESERVE", Price: 100, ReservePrice: 100}, true},
+		{"unknown", models.AuctionAccount{Mode: "ENGLISH", Price: 100}, true},
+	}
+
+	for _, tt := range tests {
+		item := tt.item
+		err := validateAuctionMode(&item)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateAuctionMode error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateAuctionModeClearsUnusedFields(t *testing.T) {
+	item := &models.AuctionAccount{Price: 100, FloorPrice: 10, PriceStep: 1, StepSeconds: 60, ReservePrice: 500}
+	if err := validateAuctionMode(item); err != nil {
+		t.Fatal(err)
+	}
+
+	if item.Mode != "FIXED" || item.FloorPrice != 0 || item.PriceStep != 0 || item.StepSeconds != 0 || item.ReservePrice != 0 {
+		t.Fatalf("fixed listing kept mode fields: %+v", item)
+	}
+
+	item = &models.AuctionAccount{Mode: "RESERVE", Price: 100, ReservePrice: 500, FloorPrice: 10}
+	if err := validateAuctionMode(item); err != nil {
+		t.Fatal(err)
+	}
+
+	if item.FloorPrice != 0 || item.ReservePrice != 500 {
+		t.Fatalf("reserve listing fields = %+v", item)
+	}
+}
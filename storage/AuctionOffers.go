@@ -0,0 +1,320 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+const defaultOfferSeconds = 24 * 60 * 60
+
+const offerColumns = `id, auctionId, sellerId, robloxId, robloxName, amount, counterAmount, priceType, status, expiresAt, created, updated`
+
+func scanOffer(row pgx.Row, offer *models.AuctionOffer) error {
+	return row.Scan(&offer.ID, &offer.AuctionID, &offer.SellerID, &offer.RobloxID, &offer.RobloxName, &offer.Amount, &offer.CounterAmount, &offer.PriceType, &offer.Status, &offer.ExpiresAt, &offer.Created, &offer.Updated)
+}
+
+func (s *PostgresStore) offerSeconds() int64 {
+	if s.cfg.OfferSeconds > 0 {
+		return s.cfg.OfferSeconds
+	}
+
+	return defaultOfferSeconds
+}
+
+// MakeOffer lets a buyer offer less than the listed price on an OPEN buy-now
+// listing. Nothing is taken from the buyer's wallet until the offer is
+// accepted. A buyer holds at most one open offer per listing.
+func (s *PostgresStore) MakeOffer(item *models.AuctionAccount) (*models.AuctionOffer, error) {
+	if item.UID == 0 {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	if item.ID == 0 || item.Name == "" {
+		return nil, fmt.Errorf("robloxId and robloxName cannot be empty")
+	}
+
+	if item.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkBan(ctx, tx, item.ID, "BUY"); err != nil {
+		return nil, err
+	}
+
+	checkQuery := `
+	SELECT robloxId, startPrice, priceType FROM auctions
+	WHERE id = $1 AND status = 'OPEN' AND NOT hidden AND mode = 'FIXED' AND expiresAt > CURRENT_TIMESTAMP AND highestBidderId = 0
+	FOR UPDATE
+	`
+
+	var sellerId, startPrice int64
+	var priceType string
+	err = tx.QueryRow(ctx, checkQuery, item.UID).Scan(&sellerId, &startPrice, &priceType)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("auction is not open for offers")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	if sellerId == item.ID {
+		return nil, fmt.Errorf("cannot make an offer on your own auction")
+	}
+
+	if item.Amount >= startPrice {
+		return nil, fmt.Errorf("offer must be less than the listed price of %d %s", startPrice, priceType)
+	}
+
+	var open bool
+	openQuery := `SELECT EXISTS (SELECT 1 FROM auction_offers WHERE auctionId = $1 AND robloxId = $2 AND status IN ('PENDING', 'COUNTERED'))`
+	if err := tx.QueryRow(ctx, openQuery, item.UID, item.ID).Scan(&open); err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	if open {
+		return nil, fmt.Errorf("you already have an open offer on this auction")
+	}
+
+	insertQuery := `
+	INSERT INTO auction_offers (auctionId, sellerId, robloxId, robloxName, amount, priceType, expiresAt)
+	SELECT id, robloxId, $2, $3, $4, priceType, LEAST(CURRENT_TIMESTAMP + make_interval(secs => $5), expiresAt)
+	FROM auctions WHERE id = $1
+	RETURNING ` + offerColumns
+
+	offer := &models.AuctionOffer{}
+	if err := scanOffer(tx.QueryRow(ctx, insertQuery, item.UID, item.ID, item.Name, item.Amount, s.offerSeconds()), offer); err != nil {
+		return nil, fmt.Errorf("unable to insert row: %w", err)
+	}
+
+	if err := recordAuctionEvent(ctx, tx, item.UID, "OFFER_MADE", item.ID, item.Name, fmt.Sprintf("%d", item.Amount)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return offer, nil
+}
+
+// lockOffer loads an open, unexpired offer for update.
+func lockOffer(ctx context.Context, tx pgx.Tx, offerId int64) (*models.AuctionOffer, error) {
+	query := `
+	SELECT ` + offerColumns + ` FROM auction_offers
+	WHERE id = $1 AND status IN ('PENDING', 'COUNTERED') AND expiresAt > CURRENT_TIMESTAMP
+	FOR UPDATE
+	`
+
+	offer := &models.AuctionOffer{}
+	err := scanOffer(tx.QueryRow(ctx, query, offerId), offer)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("offer is not open")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	return offer, nil
+}
+
+// AcceptOffer sells the listing at the agreed price. The seller accepts a
+// PENDING offer; the buyer accepts the seller's counter on a COUNTERED one.
+// The sale then goes through the same escrow and claim path as PURCHASE.
+func (s *PostgresStore) AcceptOffer(item *models.AuctionAccount) (*models.AuctionAccount, error) {
+	if item.OfferID == 0 {
+		return nil, fmt.Errorf("offerId cannot be empty")
+	}
+
+	if item.ID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	offer, err := lockOffer(ctx, tx, item.OfferID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkBan(ctx, tx, offer.RobloxID, "BUY"); err != nil {
+		return nil, err
+	}
+
+	price := offer.Amount
+	switch {
+	case offer.Status == "PENDING" && offer.SellerID == item.ID:
+	case offer.Status == "COUNTERED" && offer.RobloxID == item.ID:
+		price = offer.CounterAmount
+	default:
+		return nil, fmt.Errorf("robloxId cannot accept this offer")
+	}
+
+	query := `
+	UPDATE auctions SET status = 'PURCHASED', buyerId = $2, buyerName = $3, purchased = CURRENT_TIMESTAMP, soldPrice = $4, escrowed = $4,
+		reservedBy = 0, reservedUntil = NULL
+	WHERE id = $1 AND status = 'OPEN' AND NOT hidden AND mode = 'FIXED' AND expiresAt > CURRENT_TIMESTAMP AND highestBidderId = 0
+		AND (reservedBy = 0 OR reservedBy = $2 OR reservedUntil <= CURRENT_TIMESTAMP)
+	RETURNING ` + auctionColumns
+
+	purchased := &models.AuctionAccount{}
+	err = scanAuction(tx.QueryRow(ctx, query, offer.AuctionID, offer.RobloxID, offer.RobloxName, price), purchased)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("auction is not available for purchase")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE auction_offers SET status = 'ACCEPTED', updated = CURRENT_TIMESTAMP WHERE id = $1`, offer.ID); err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	if err := s.settlePurchase(ctx, tx, purchased, fmt.Sprintf("OFFER:%d", offer.ID)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return purchased, nil
+}
+
+// RejectOffer closes an offer: the seller rejects a PENDING offer, the buyer
+// rejects a counter.
+func (s *PostgresStore) RejectOffer(item *models.AuctionAccount) (*models.AuctionOffer, error) {
+	query := `
+	UPDATE auction_offers SET status = 'REJECTED', updated = CURRENT_TIMESTAMP
+	WHERE id = $1 AND expiresAt > CURRENT_TIMESTAMP
+		AND ((status = 'PENDING' AND sellerId = $2) OR (status = 'COUNTERED' AND robloxId = $2))
+	RETURNING ` + offerColumns
+
+	return s.updateOffer(item, query, "robloxId cannot reject this offer")
+}
+
+// CounterOffer lets the seller answer a PENDING offer with their own price,
+// which the buyer can then accept or reject. The counter gets a fresh expiry.
+func (s *PostgresStore) CounterOffer(item *models.AuctionAccount) (*models.AuctionOffer, error) {
+	if item.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	query := `
+	UPDATE auction_offers o SET status = 'COUNTERED', counterAmount = $3, updated = CURRENT_TIMESTAMP,
+		expiresAt = LEAST(CURRENT_TIMESTAMP + make_interval(secs => $4), a.expiresAt)
+	FROM auctions a
+	WHERE o.id = $1 AND a.id = o.auctionId AND a.status = 'OPEN' AND o.status = 'PENDING' AND o.sellerId = $2
+		AND o.expiresAt > CURRENT_TIMESTAMP AND $3 > o.amount AND $3 < a.startPrice
+	RETURNING o.id, o.auctionId, o.sellerId, o.robloxId, o.robloxName, o.amount, o.counterAmount, o.priceType, o.status, o.expiresAt, o.created, o.updated
+	`
+
+	return s.updateOffer(item, query, "robloxId cannot counter this offer at that amount", item.Amount, s.offerSeconds())
+}
+
+// CancelOffer withdraws a buyer's own open offer.
+func (s *PostgresStore) CancelOffer(item *models.AuctionAccount) (*models.AuctionOffer, error) {
+	query := `
+	UPDATE auction_offers SET status = 'CANCELLED', updated = CURRENT_TIMESTAMP
+	WHERE id = $1 AND robloxId = $2 AND status IN ('PENDING', 'COUNTERED')
+	RETURNING ` + offerColumns
+
+	return s.updateOffer(item, query, "robloxId has no open offer with that offerId")
+}
+
+// updateOffer runs a single offer transition. query takes the offer id and
+// the acting robloxId, followed by args.
+func (s *PostgresStore) updateOffer(item *models.AuctionAccount, query string, notFound string, args ...any) (*models.AuctionOffer, error) {
+	if item.OfferID == 0 {
+		return nil, fmt.Errorf("offerId cannot be empty")
+	}
+
+	if item.ID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	offer := &models.AuctionOffer{}
+	err := scanOffer(s.db.QueryRow(context.Background(), query, append([]any{item.OfferID, item.ID}, args...)...), offer)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.New(notFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	return offer, nil
+}
+
+// GetOffers returns the offers on a listing when id is set, otherwise every
+// offer the player has made or received.
+func (s *PostgresStore) GetOffers(item *models.AuctionAccount) ([]*models.AuctionOffer, error) {
+	if item.ID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	query := `
+	SELECT ` + offerColumns + ` FROM auction_offers
+	WHERE (robloxId = $1 OR sellerId = $1) AND ($2 = 0 OR auctionId = $2)
+	ORDER BY id DESC
+	LIMIT $3
+	`
+
+	rows, err := s.db.Query(context.Background(), query, item.ID, item.UID, LIMIT)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	offers := make([]*models.AuctionOffer, 0)
+
+	for rows.Next() {
+		offer := &models.AuctionOffer{}
+		if err := scanOffer(rows, offer); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		offers = append(offers, offer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return offers, nil
+}
+
+// cancelPendingOffers closes every open offer on a listing that has been
+// sold, expired or removed.
+func cancelPendingOffers(ctx context.Context, tx pgx.Tx, auctionId int64) error {
+	query := `UPDATE auction_offers SET status = 'CANCELLED', updated = CURRENT_TIMESTAMP WHERE auctionId = $1 AND status IN ('PENDING', 'COUNTERED')`
+
+	if _, err := tx.Exec(ctx, query, auctionId); err != nil {
+		return fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PostgresStore) expireOffers() {
+	query := `UPDATE auction_offers SET status = 'EXPIRED', updated = CURRENT_TIMESTAMP WHERE status IN ('PENDING', 'COUNTERED') AND expiresAt <= CURRENT_TIMESTAMP`
+
+	if _, err := s.db.Exec(context.Background(), query); err != nil {
+		fmt.Println("Failed to expire offers:", err)
+	}
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+const defaultProceedsDeliveryDays = 14
+
+func (s *PostgresStore) proceedsDeliveryDays() int64 {
+	if s.cfg.ProceedsDeliveryDays > 0 {
+		return s.cfg.ProceedsDeliveryDays
+	}
+
+	return defaultProceedsDeliveryDays
+}
+
+// deliverUnclaimedProceeds settles sales left open for the configured number
+// of days: unclaimed proceeds go to the seller and an unclaimed item to the
+// buyer, so the row can close as CLAIMED.
+func (s *PostgresStore) deliverUnclaimedProceeds() {
+	query := `
+	SELECT id FROM auctions
+	WHERE status = 'PURCHASED' AND (NOT proceedsClaimed OR NOT itemClaimed) AND purchased <= CURRENT_TIMESTAMP - make_interval(days => $1)
+	`
+
+	rows, err := s.db.Query(context.Background(), query, s.proceedsDeliveryDays())
+	if err != nil {
+		fmt.Printf("unable to query database: %v\n", err)
+		return
+	}
+
+	var unclaimed []int64
+	for rows.Next() {
+		var uid int64
+		if err := rows.Scan(&uid); err != nil {
+			rows.Close()
+			fmt.Printf("unable to query database: %v\n", err)
+			return
+		}
+
+		unclaimed = append(unclaimed, uid)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		fmt.Printf("unable to query database: %v\n", err)
+		return
+	}
+
+	for _, uid := range unclaimed {
+		if err := s.deliverProceeds(uid); err != nil {
+			fmt.Printf("Failed to deliver proceeds for auction %d: %v\n", uid, err)
+		}
+	}
+}
+
+// deliverProceeds claims whatever is left of one sale on both parties'
+// behalf and marks the row CLAIMED. Unclaimed proceeds are taxed as in
+// AuctionClaim and released from escrow straight to the seller's mailbox. An
+// unclaimed item is mailed to the buyer.
+func (s *PostgresStore) deliverProceeds(uid int64) error {
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	lockQuery := `SELECT proceedsClaimed, itemClaimed FROM auctions WHERE id = $1 AND status = 'PURCHASED' FOR UPDATE`
+
+	var proceedsClaimed, itemClaimed bool
+	err = tx.QueryRow(ctx, lockQuery, uid).Scan(&proceedsClaimed, &itemClaimed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	query := `
+	UPDATE auctions SET proceedsClaimed = true, itemClaimed = true, status = 'CLAIMED'
+	WHERE id = $1
+	RETURNING ` + auctionColumns
+
+	claimed := &models.AuctionAccount{}
+	if err := scanAuction(tx.QueryRow(ctx, query, uid), claimed); err != nil {
+		return fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	if !proceedsClaimed {
+		if err := s.mailProceeds(ctx, tx, claimed); err != nil {
+			return err
+		}
+	}
+
+	if !itemClaimed {
+		if err := s.mailItem(ctx, tx, claimed); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// mailProceeds pays the seller's side of a sale through the mailbox.
+func (s *PostgresStore) mailProceeds(ctx context.Context, tx pgx.Tx, claimed *models.AuctionAccount) error {
+	if err := recordAuctionEvent(ctx, tx, claimed.UID, "CLAIMED", 0, systemActor, "PROCEEDS_AUTO"); err != nil {
+		return err
+	}
+
+	if err := s.collectSaleTax(ctx, tx, claimed, false); err != nil {
+		return err
+	}
+
+	if claimed.NetProceeds <= 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("Your auction sold for %d %s. The unclaimed proceeds have been sent to your mailbox.", claimed.SoldPrice, claimed.PriceType)
+	mail, err := newMailbox(claimed.ID, claimed.Name, message, currencyItem(claimed.PriceType, claimed.NetProceeds))
+	if err != nil {
+		return err
+	}
+
+	return enqueueMailbox(ctx, tx, fmt.Sprintf("auction:%d:proceeds", claimed.UID), mail)
+}
+
+// mailItem delivers the buyer's side of a sale through the mailbox.
+func (s *PostgresStore) mailItem(ctx context.Context, tx pgx.Tx, claimed *models.AuctionAccount) error {
+	if err := recordAuctionEvent(ctx, tx, claimed.UID, "CLAIMED", 0, systemActor, "ITEM_AUTO"); err != nil {
+		return err
+	}
+
+	if err := s.recordProvenance(ctx, tx, claimed.UID, "CLAIMED", claimed.BuyerID); err != nil {
+		return err
+	}
+
+	var itemData map[string]interface{}
+	if err := json.Unmarshal(claimed.ItemData, &itemData); err != nil {
+		return fmt.Errorf("unable to unmarshal itemData: %w", err)
+	}
+
+	mail, err := newMailbox(claimed.BuyerID, claimed.BuyerName, "The item you bought was not claimed and has been sent to your mailbox.", itemData)
+	if err != nil {
+		return err
+	}
+
+	return enqueueMailbox(ctx, tx, fmt.Sprintf("auction:%d:item", claimed.UID), mail)
+}
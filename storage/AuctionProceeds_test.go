@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestDeliverUnclaimedProceedsMailsBothSides(t *testing.T) {
+	s := newTestStore(t)
+
+	const seller, buyer = 10, 20
+	uid := insertTestAuction(t, s, seller, 100)
+
+	inTx(t, s, func(ctx context.Context, tx pgx.Tx) error {
+		query := `
+		UPDATE auctions SET status = 'PURCHASED', buyerId = $2, buyerName = 'buyer', soldPrice = 100, escrowed = 100,
+			purchased = CURRENT_TIMESTAMP - INTERVAL '30 days'
+		WHERE id = $1
+		`
+		if _, err := tx.Exec(ctx, query, uid, buyer); err != nil {
+			return err
+		}
+
+		_, err := adjustWallet(ctx, tx, escrowAccount, "Coins", 100, "ESCROW_HOLD", uid, "")
+		return err
+	})
+
+	s.deliverUnclaimedProceeds()
+
+	var status string
+	err := s.db.QueryRow(context.Background(), `SELECT status FROM auctions WHERE id = $1`, uid).Scan(&status)
+	if err != nil {
+		t.Fatalf("select auction: %v", err)
+	}
+
+	if status != "CLAIMED" {
+		t.Fatalf("status = %s, want CLAIMED", status)
+	}
+
+	if got := walletBalance(t, s, escrowAccount, "Coins"); got != 0 {
+		t.Errorf("escrow balance = %d, want 0", got)
+	}
+
+	if got := walletBalance(t, s, seller, "Coins"); got != 0 {
+		t.Errorf("seller wallet = %d, want 0: proceeds go to the mailbox", got)
+	}
+
+	var sellerLedger int64
+	err = s.db.QueryRow(context.Background(), `SELECT COUNT(*) FROM wallet_ledger WHERE robloxId = $1`, seller).Scan(&sellerLedger)
+	if err != nil {
+		t.Fatalf("select ledger: %v", err)
+	}
+
+	if sellerLedger != 0 {
+		t.Errorf("seller has %d ledger rows, want none", sellerLedger)
+	}
+
+	for _, key := range []string{fmt.Sprintf("auction:%d:proceeds", uid), fmt.Sprintf("auction:%d:item", uid)} {
+		var robloxId int64
+		var payload json.RawMessage
+		err := s.db.QueryRow(context.Background(), `SELECT robloxId, payload FROM mailbox_outbox WHERE idempotencyKey = $1`, key).Scan(&robloxId, &payload)
+		if err != nil {
+			t.Fatalf("outbox row %s: %v", key, err)
+		}
+
+		want := int64(seller)
+		if key == fmt.Sprintf("auction:%d:item", uid) {
+			want = buyer
+		}
+
+		if robloxId != want {
+			t.Errorf("%s sent to %d, want %d", key, robloxId, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+const (
+	defaultReservationSeconds  = 60
+	defaultReservationCooldown = 5 * 60
+)
+
+// heldReservationUntil keeps the end of a hold the buyer in $2 already has,
+// or starts a new one of $3 seconds that never outlives the listing.
+const heldReservationUntil = `CASE WHEN reservedBy = $2 AND reservedUntil > CURRENT_TIMESTAMP THEN reservedUntil
+		ELSE LEAST(CURRENT_TIMESTAMP + make_interval(secs => $3), expiresAt) END`
+
+func (s *PostgresStore) reservationCooldown() int64 {
+	if s.cfg.ReservationCooldown > 0 {
+		return s.cfg.ReservationCooldown
+	}
+
+	return defaultReservationCooldown
+}
+
+// ReserveAuction holds an OPEN buy-now listing for one buyer so the game can
+// check their balance before confirming. Reserving again while the hold is
+// live returns it unchanged, and once it ends the same buyer has to wait out
+// the cooldown before reserving the listing again.
+func (s *PostgresStore) ReserveAuction(item *models.AuctionAccount) (*models.AuctionAccount, error) {
+	if item.UID == 0 {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	if item.ID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	maxSeconds := s.cfg.ReservationSeconds
+	if maxSeconds <= 0 {
+		maxSeconds = defaultReservationSeconds
+	}
+
+	seconds := item.Seconds
+	if seconds <= 0 || seconds > maxSeconds {
+		seconds = maxSeconds
+	}
+
+	if err := checkBan(context.Background(), s.db, item.ID, "BUY"); err != nil {
+		return nil, err
+	}
+
+	query := `
+	UPDATE auctions SET reservedBy = $2, reservedUntil = ` + heldReservationUntil + `,
+		reserveCooldownBy = $2, reserveCooldownUntil = ` + heldReservationUntil + ` + make_interval(secs => $4)
+	WHERE id = $1 AND status = 'OPEN' AND NOT hidden AND mode <> 'RESERVE' AND expiresAt > CURRENT_TIMESTAMP AND highestBidderId = 0 AND robloxId <> $2
+		AND ((reservedBy = $2 AND reservedUntil > CURRENT_TIMESTAMP)
+			OR ((reservedBy = 0 OR reservedUntil <= CURRENT_TIMESTAMP) AND NOT (reserveCooldownBy = $2 AND reserveCooldownUntil > CURRENT_TIMESTAMP)))
+	RETURNING ` + auctionColumns
+
+	reserved := &models.AuctionAccount{}
+	err := scanAuction(s.db.QueryRow(context.Background(), query, item.UID, item.ID, seconds, s.reservationCooldown()), reserved)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("auction is not available for reservation")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	return reserved, nil
+}
+
+// ConfirmAuction completes the purchase of a listing the buyer has reserved.
+func (s *PostgresStore) ConfirmAuction(item *models.AuctionAccount) (*models.AuctionAccount, error) {
+	return s.purchaseAuction(item, true)
+}
+
+func (s *PostgresStore) ReleaseAuction(item *models.AuctionAccount) error {
+	if item.UID == 0 {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	if item.ID == 0 {
+		return fmt.Errorf("robloxId cannot be empty")
+	}
+
+	query := `UPDATE auctions SET reservedBy = 0, reservedUntil = NULL WHERE id = $1 AND status = 'OPEN' AND reservedBy = $2`
+
+	result, err := s.db.Exec(context.Background(), query, item.UID, item.ID)
+	if err != nil {
+		return fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("robloxId does not hold a reservation on id")
+	}
+
+	return nil
+}
+
+// releaseLapsedReservations clears holds nobody confirmed or released. Lapsed
+// holds are already ignored by every check, this only tidies the rows.
+func (s *PostgresStore) releaseLapsedReservations() {
+	query := `UPDATE auctions SET reservedBy = 0, reservedUntil = NULL WHERE reservedBy <> 0 AND reservedUntil <= CURRENT_TIMESTAMP`
+
+	if _, err := s.db.Exec(context.Background(), query); err != nil {
+		fmt.Println("Failed to release lapsed reservations:", err)
+	}
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kattah7/v3/models"
+)
+
+// GetEconomyReport summarises auction activity per day and priceType. It
+// reads auction_events rather than auctions, so listings that have since been
+// unlisted or deleted are still counted.
+func (s *PostgresStore) GetEconomyReport(req *models.EconomyReportRequest) ([]*models.EconomyDay, error) {
+	query := `
+	WITH events AS (
+		SELECT date_trunc('day', created) AS day, priceType, event, itemType, price
+		FROM auction_events
+		WHERE created > date_trunc('day', CURRENT_TIMESTAMP) - make_interval(days => $1) AND ($2 = '' OR priceType = $2)
+			AND event IN ('LISTED', 'PURCHASED', 'UNLISTED', 'EXPIRED')
+	),
+	top AS (
+		SELECT DISTINCT ON (day, priceType) day, priceType, itemType
+		FROM events WHERE event = 'PURCHASED'
+		GROUP BY day, priceType, itemType
+		ORDER BY day, priceType, COUNT(*) DESC, itemType
+	)
+	SELECT e.day, e.priceType,
+		COUNT(*) FILTER (WHERE e.event = 'LISTED'),
+		COUNT(*) FILTER (WHERE e.event = 'PURCHASED'),
+		COUNT(*) FILTER (WHERE e.event = 'UNLISTED'),
+		COUNT(*) FILTER (WHERE e.event = 'EXPIRED'),
+		COALESCE(SUM(e.price) FILTER (WHERE e.event = 'PURCHASED'), 0)::BIGINT,
+		COALESCE(AVG(e.price) FILTER (WHERE e.event = 'PURCHASED'), 0)::BIGINT,
+		COALESCE(t.itemType, '')
+	FROM events e
+	LEFT JOIN top t ON t.day = e.day AND t.priceType = e.priceType
+	GROUP BY e.day, e.priceType, t.itemType
+	ORDER BY e.day DESC, e.priceType
+	`
+
+	rows, err := s.db.Query(context.Background(), query, reportDays(req.Days)-1, req.PriceType)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	report := make([]*models.EconomyDay, 0)
+
+	for rows.Next() {
+		day := &models.EconomyDay{}
+		err := rows.Scan(&day.Day, &day.PriceType, &day.Listed, &day.Sales, &day.Unlisted, &day.Expired,
+			&day.Volume, &day.AverageSalePrice, &day.TopItemType)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		report = append(report, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return report, nil
+}
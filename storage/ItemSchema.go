@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// itemSchema is the subset of JSON Schema supported for itemData: type,
+// properties, required, additionalProperties, enum, minimum, maximum,
+// minLength, maxLength, pattern and items.
+type itemSchema struct {
+	Type                 string                 `json:"type"`
+	Properties           map[string]*itemSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Enum                 []interface{}          `json:"enum"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	Items                *itemSchema            `json:"items"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaKeywords are the keywords itemSchema enforces, plus annotations that
+// don't affect validation.
+var schemaKeywords = map[string]bool{
+	"type": true, "properties": true, "required": true, "additionalProperties": true, "enum": true,
+	"minimum": true, "maximum": true, "minLength": true, "maxLength": true, "pattern": true, "items": true,
+	"$schema": true, "title": true, "description": true,
+}
+
+var schemaTypes = map[string]bool{"": true, "object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true}
+
+// parseItemSchema decodes and checks a schema so that problems are reported
+// when an admin saves it rather than when a player lists an item.
+func parseItemSchema(raw json.RawMessage) (*itemSchema, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		raw = json.RawMessage(`{"type": "object"}`)
+	}
+
+	schema := &itemSchema{}
+	if err := json.Unmarshal(raw, schema); err != nil {
+		return nil, fmt.Errorf("schema must be a JSON object: %w", err)
+	}
+
+	if schema.Type != "object" {
+		return nil, fmt.Errorf("schema type must be object")
+	}
+
+	if err := schema.compile("schema"); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// checkSchemaKeywords rejects keywords itemSchema would silently ignore, such
+// as oneOf or $ref, so an admin can't save a rule that is never enforced.
+// Schemas already stored are loaded without this check.
+func checkSchemaKeywords(raw json.RawMessage) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var node interface{}
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return fmt.Errorf("schema must be a JSON object: %w", err)
+	}
+
+	return checkSchemaNode("schema", node)
+}
+
+func checkSchemaNode(path string, node interface{}) error {
+	obj, ok := node.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !schemaKeywords[key] {
+			return fmt.Errorf("%s: unsupported keyword %q", path, key)
+		}
+	}
+
+	if properties, ok := obj["properties"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if err := checkSchemaNode(path+"."+name, properties[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return checkSchemaNode(path+"[]", obj["items"])
+}
+
+func (schema *itemSchema) compile(path string) error {
+	if !schemaTypes[schema.Type] {
+		return fmt.Errorf("%s: unsupported type %q", path, schema.Type)
+	}
+
+	if schema.Pattern != "" {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", path, err)
+		}
+
+		schema.pattern = pattern
+	}
+
+	for name, property := range schema.Properties {
+		if property == nil {
+			return fmt.Errorf("%s.%s: schema cannot be null", path, name)
+		}
+
+		if err := property.compile(path + "." + name); err != nil {
+			return err
+		}
+	}
+
+	if schema.Items != nil {
+		return schema.Items.compile(path + "[]")
+	}
+
+	return nil
+}
+
+// validate checks itemData against the schema and returns one error listing
+// every failing field.
+func (schema *itemSchema) validate(itemData json.RawMessage) error {
+	var value interface{}
+	if err := json.Unmarshal(itemData, &value); err != nil {
+		return fmt.Errorf("itemData must be valid JSON")
+	}
+
+	var problems []string
+	schema.check("itemData", value, &problems)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid itemData: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func (schema *itemSchema) check(path string, value interface{}, problems *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if len(schema.Enum) > 0 && !schemaEnumContains(schema.Enum, value) {
+		fail("must be one of %v", schema.Enum)
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if schema.Type != "" && schema.Type != "object" {
+			fail("must be %s", schemaTypeName(schema.Type))
+			return
+		}
+
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				*problems = append(*problems, path+"."+name+": is required")
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					*problems = append(*problems, path+"."+name+": is not allowed")
+				}
+				continue
+			}
+
+			property.check(path+"."+name, v[name], problems)
+		}
+	case []interface{}:
+		if schema.Type != "" && schema.Type != "array" {
+			fail("must be %s", schemaTypeName(schema.Type))
+			return
+		}
+
+		if schema.Items != nil {
+			for i, element := range v {
+				schema.Items.check(fmt.Sprintf("%s[%d]", path, i), element, problems)
+			}
+		}
+	case string:
+		if schema.Type != "" && schema.Type != "string" {
+			fail("must be %s", schemaTypeName(schema.Type))
+			return
+		}
+
+		length := len([]rune(v))
+		if schema.MinLength != nil && length < *schema.MinLength {
+			fail("must be at least %d characters", *schema.MinLength)
+		}
+
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			fail("must be at most %d characters", *schema.MaxLength)
+		}
+
+		if schema.pattern != nil && !schema.pattern.MatchString(v) {
+			fail("must match %s", schema.Pattern)
+		}
+	case float64:
+		if schema.Type != "" && schema.Type != "number" && schema.Type != "integer" {
+			fail("must be %s", schemaTypeName(schema.Type))
+			return
+		}
+
+		if schema.Type == "integer" && v != math.Trunc(v) {
+			fail("must be an integer")
+		}
+
+		if schema.Minimum != nil && v < *schema.Minimum {
+			fail("must be at least %v", *schema.Minimum)
+		}
+
+		if schema.Maximum != nil && v > *schema.Maximum {
+			fail("must be at most %v", *schema.Maximum)
+		}
+	case bool:
+		if schema.Type != "" && schema.Type != "boolean" {
+			fail("must be %s", schemaTypeName(schema.Type))
+		}
+	case nil:
+		if schema.Type != "" {
+			fail("must be %s", schemaTypeName(schema.Type))
+		}
+	}
+}
+
+func schemaTypeName(schemaType string) string {
+	switch schemaType {
+	case "object", "array", "integer":
+		return "an " + schemaType
+	}
+
+	return "a " + schemaType
+}
+
+func schemaEnumContains(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(allowed, value) {
+			return true
+		}
+	}
+
+	return false
+}
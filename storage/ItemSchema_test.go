@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const petSchema = `{
+	"type": "object",
+	"required": ["petId", "name"],
+	"additionalProperties": false,
+	"properties": {
+		"petId": {"type": "integer", "minimum": 1},
+		"name": {"type": "string", "minLength": 1, "maxLength": 20},
+		"shiny": {"type": "boolean"},
+		"rarity": {"enum": ["COMMON", "RARE", "LEGENDARY"]},
+		"uuid": {"type": "string", "pattern": "^[0-9a-f-]{36}$"},
+		"tags": {"type": "array", "items": {"type": "string"}}
+	}
+}`
+
+func TestParseItemSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{"empty defaults to object", ``, false},
+		{"pet", petSchema, false},
+		{"not an object", `{"type": "string"}`, true},
+		{"unsupported type", `{"type": "object", "properties": {"a": {"type": "date"}}}`, true},
+		{"bad pattern", `{"type": "object", "properties": {"a": {"type": "string", "pattern": "("}}}`, true},
+		{"null property", `{"type": "object", "properties": {"a": null}}`, true},
+	}
+
+	for _, tt := range tests {
+		_, err := parseItemSchema(json.RawMessage(tt.schema))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseItemSchema error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckSchemaKeywords(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{"empty", ``, ""},
+		{"pet", petSchema, ""},
+		{"annotations", `{"$schema": "x", "title": "Pet", "type": "object", "properties": {"a": {"description": "b"}}}`, ""},
+		{"top level", `{"type": "object", "oneOf": []}`, `schema: unsupported keyword "oneOf"`},
+		{"property", `{"type": "object", "properties": {"id": {"type": "string", "format": "uuid"}}}`, `schema.id: unsupported keyword "format"`},
+		{"items", `{"type": "object", "properties": {"tags": {"type": "array", "items": {"const": "a"}}}}`, `schema.tags[]: unsupported keyword "const"`},
+		{"ref", `{"type": "object", "properties": {"a": {"$ref": "#/x"}}}`, `schema.a: unsupported keyword "$ref"`},
+		{"min items", `{"type": "object", "properties": {"a": {"type": "array", "minItems": 1}}}`, `schema.a: unsupported keyword "minItems"`},
+	}
+
+	for _, tt := range tests {
+		err := checkSchemaKeywords(json.RawMessage(tt.schema))
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestItemSchemaValidate(t *testing.T) {
+	schema, err := parseItemSchema(json.RawMessage(petSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		itemData string
+		wantErr  string
+	}{
+		{"valid", `{"petId": 3, "name": "Dog", "shiny": true, "rarity": "RARE", "tags": ["a"]}`, ""},
+		{"missing required", `{"petId": 3}`, "itemData.name: is required"},
+		{"wrong type", `{"petId": "3", "name": "Dog"}`, "itemData.petId: must be an integer"},
+		{"not an integer", `{"petId": 1.5, "name": "Dog"}`, "itemData.petId: must be an integer"},
+		{"below minimum", `{"petId": 0, "name": "Dog"}`, "itemData.petId: must be at least 1"},
+		{"too long", `{"petId": 3, "name": "` + strings.Repeat("x", 21) + `"}`, "itemData.name: must be at most 20 characters"},
+		{"not in enum", `{"petId": 3, "name": "Dog", "rarity": "MYTHIC"}`, "itemData.rarity: must be one of"},
+		{"pattern", `{"petId": 3, "name": "Dog", "uuid": "nope"}`, "itemData.uuid: must match"},
+		{"array items", `{"petId": 3, "name": "Dog", "tags": [1]}`, "itemData.tags[0]: must be a string"},
+		{"additional property", `{"petId": 3, "name": "Dog", "extra": 1}`, "itemData.extra: is not allowed"},
+		{"not json", `{`, "itemData must be valid JSON"},
+	}
+
+	for _, tt := range tests {
+		err := schema.validate(json.RawMessage(tt.itemData))
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error = %v, want it to contain %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+const (
+	defaultListingTier  = "default"
+	defaultListingLimit = 5
+)
+
+func (s *PostgresStore) listingLimit(tier string) int64 {
+	if limit, ok := s.cfg.ListingLimits[tier]; ok {
+		return limit
+	}
+
+	if limit, ok := s.cfg.ListingLimits[defaultListingTier]; ok {
+		return limit
+	}
+
+	return defaultListingLimit
+}
+
+func (s *PostgresStore) validTier(tier string) bool {
+	if tier == defaultListingTier {
+		return true
+	}
+
+	_, ok := s.cfg.ListingLimits[tier]
+	return ok
+}
+
+// listingTier uses the tier the game server supplied, falling back to the one
+// stored for the player and then to the default tier.
+func (s *PostgresStore) listingTier(ctx context.Context, tx pgx.Tx, robloxId int64, requested string) (string, error) {
+	if requested != "" {
+		if !s.validTier(requested) {
+			return "", fmt.Errorf("unknown tier %q", requested)
+		}
+
+		return requested, nil
+	}
+
+	var tier string
+	err := tx.QueryRow(ctx, `SELECT tier FROM player_tiers WHERE robloxId = $1`, robloxId).Scan(&tier)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return defaultListingTier, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	return tier, nil
+}
+
+// checkListingLimit makes sure the seller has room for adding more OPEN
+// listings. It takes a per-seller lock for the rest of tx, so concurrent LIST
+// calls for the same seller are counted one after another.
+func (s *PostgresStore) checkListingLimit(ctx context.Context, tx pgx.Tx, robloxId int64, requestedTier string, adding int64) error {
+	tier, err := s.listingTier(ctx, tx, robloxId, requestedTier)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, robloxId); err != nil {
+		return fmt.Errorf("Unable to lock seller: %w", err)
+	}
+
+	var open int64
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM auctions WHERE robloxId = $1 AND status = 'OPEN'`, robloxId).Scan(&open); err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	limit := s.listingLimit(tier)
+	remaining := limit - open
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	if adding > remaining {
+		return fmt.Errorf("listing limit reached: %d of %d slots remaining for tier %s", remaining, limit, tier)
+	}
+
+	return nil
+}
+
+func (s *PostgresStore) GetPlayerTier(account *models.PlayerTier) (*models.PlayerTier, error) {
+	if account.RobloxID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	tier := &models.PlayerTier{RobloxID: account.RobloxID, Tier: defaultListingTier}
+
+	err := s.db.QueryRow(context.Background(), `SELECT tier FROM player_tiers WHERE robloxId = $1`, account.RobloxID).Scan(&tier.Tier)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	tier.ListingLimit = s.listingLimit(tier.Tier)
+
+	return tier, nil
+}
+
+// SetPlayerTier stores the tier used when the game server doesn't supply one.
+// Setting the default tier removes the stored row.
+func (s *PostgresStore) SetPlayerTier(account *models.PlayerTier) (*models.PlayerTier, error) {
+	if account.RobloxID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	if account.Tier == "" || account.Tier == defaultListingTier {
+		if _, err := s.db.Exec(context.Background(), `DELETE FROM player_tiers WHERE robloxId = $1`, account.RobloxID); err != nil {
+			return nil, fmt.Errorf("Unable to delete row: %w", err)
+		}
+
+		return s.GetPlayerTier(account)
+	}
+
+	if !s.validTier(account.Tier) {
+		return nil, fmt.Errorf("unknown tier %q", account.Tier)
+	}
+
+	query := `
+	INSERT INTO player_tiers (robloxId, tier) VALUES ($1, $2)
+	ON CONFLICT (robloxId) DO UPDATE SET tier = EXCLUDED.tier
+	`
+
+	if _, err := s.db.Exec(context.Background(), query, account.RobloxID, account.Tier); err != nil {
+		return nil, fmt.Errorf("unable to insert row: %w", err)
+	}
+
+	return s.GetPlayerTier(account)
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kattah7/v3/models"
+)
+
+// newMailbox wraps a single item in the envelope the mailbox service expects.
+func newMailbox(robloxId int64, robloxName string, message string, item map[string]interface{}) (*models.MailboxExpire, error) {
+	item["timestamp"] = time.Now().Unix()
+	item["message"] = message
+	item["senderId"] = 1
+	item["senderName"] = "PlayCrate"
+	item["displayName"] = "PlayCrate"
+	item["targetId"] = robloxId
+
+	itemData, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal itemData: %w", err)
+	}
+
+	return &models.MailboxExpire{
+		RobloxName: robloxName,
+		RobloxId:   robloxId,
+		Type:       "ADD",
+		Payload:    json.RawMessage(fmt.Sprintf("[%s]", itemData)),
+	}, nil
+}
+
+// currencyItem builds a mailbox item that hands currency back to a player.
+func currencyItem(priceType string, amount int64) map[string]interface{} {
+	return map[string]interface{}{
+		"itemType":  "CURRENCY",
+		"priceType": priceType,
+		"amount":    amount,
+	}
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+const (
+	defaultMailboxMaxAttempts = 8
+	mailboxBaseBackoff        = 30 * time.Second
+	mailboxMaxBackoff         = time.Hour
+	mailboxBatchSize          = 100
+)
+
+const outboxColumns = `id, idempotencyKey, robloxId, robloxName, payload, status, attempts, nextAttempt, lastError, created, sent`
+
+func scanOutbox(row pgx.Row, mail *models.MailboxOutbox) error {
+	return row.Scan(&mail.ID, &mail.IdempotencyKey, &mail.RobloxID, &mail.RobloxName, &mail.Payload, &mail.Status, &mail.Attempts, &mail.NextAttempt, &mail.LastError, &mail.Created, &mail.Sent)
+}
+
+// enqueueMailbox queues a delivery inside the transaction that caused it, so
+// a state change and its mailbox delivery are committed together. Queuing the
+// same key twice is a no-op.
+func enqueueMailbox(ctx context.Context, tx pgx.Tx, idempotencyKey string, mail *models.MailboxExpire) error {
+	payload, err := json.Marshal(mail)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mailbox data: %w", err)
+	}
+
+	query := `
+	INSERT INTO mailbox_outbox (idempotencyKey, robloxId, robloxName, payload)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (idempotencyKey) DO NOTHING
+	`
+
+	if _, err := tx.Exec(ctx, query, idempotencyKey, mail.RobloxId, mail.RobloxName, payload); err != nil {
+		return fmt.Errorf("unable to insert outbox row: %w", err)
+	}
+
+	return nil
+}
+
+// deliverMailboxOutbox sends due deliveries one at a time until the queue is
+// drained or a batch has been processed.
+func (s *PostgresStore) deliverMailboxOutbox() {
+	for i := 0; i < mailboxBatchSize; i++ {
+		delivered, err := s.deliverNextMailbox()
+		if err != nil {
+			fmt.Println("Failed to deliver mailbox:", err)
+			return
+		}
+
+		if !delivered {
+			return
+		}
+	}
+}
+
+// deliverNextMailbox locks the oldest due row, sends it and records the
+// outcome. Failed rows are retried with exponential backoff and dead-lettered
+// after the configured number of attempts.
+func (s *PostgresStore) deliverNextMailbox() (bool, error) {
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	SELECT ` + outboxColumns + ` FROM mailbox_outbox
+	WHERE status = 'PENDING' AND nextAttempt <= CURRENT_TIMESTAMP
+	ORDER BY id
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+	`
+
+	row := &models.MailboxOutbox{}
+	err = scanOutbox(tx.QueryRow(ctx, query), row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	// A payload that can't be decoded will never send. Dead-letter it so the
+	// rows queued behind it still go out.
+	mail := &models.MailboxExpire{}
+	if err := json.Unmarshal(row.Payload, mail); err != nil {
+		lastError := fmt.Sprintf("failed to unmarshal outbox payload: %v", err)
+		if _, err := tx.Exec(ctx, `UPDATE mailbox_outbox SET status = 'DEAD', lastError = $2 WHERE id = $1`, row.ID, lastError); err != nil {
+			return false, fmt.Errorf("Unable to update row: %w", err)
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return false, fmt.Errorf("Unable to commit transaction: %w", err)
+		}
+
+		fmt.Printf("Mailbox delivery %s dead-lettered: %s\n", row.IdempotencyKey, lastError)
+		return true, nil
+	}
+
+	attempts := row.Attempts + 1
+	sendErr := s.mailbox.Send(ctx, mail, row.IdempotencyKey)
+
+	switch nextMailboxStatus(sendErr, attempts, s.mailboxMaxAttempts()) {
+	case "SENT":
+		_, err = tx.Exec(ctx, `UPDATE mailbox_outbox SET status = 'SENT', attempts = $2, lastError = '', sent = CURRENT_TIMESTAMP WHERE id = $1`, row.ID, attempts)
+	case "DEAD":
+		_, err = tx.Exec(ctx, `UPDATE mailbox_outbox SET status = 'DEAD', attempts = $2, lastError = $3 WHERE id = $1`, row.ID, attempts, sendErr.Error())
+	case "DRY_RUN":
+		_, err = tx.Exec(ctx, `UPDATE mailbox_outbox SET status = 'DRY_RUN', lastError = $2 WHERE id = $1`, row.ID, sendErr.Error())
+	default:
+		backoff := mailboxBackoff(attempts)
+		_, err = tx.Exec(ctx, `UPDATE mailbox_outbox SET attempts = $2, lastError = $3, nextAttempt = CURRENT_TIMESTAMP + make_interval(secs => $4) WHERE id = $1`, row.ID, attempts, sendErr.Error(), backoff.Seconds())
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	if sendErr != nil && !errors.Is(sendErr, ErrMailboxDryRun) {
+		fmt.Printf("Mailbox delivery %s failed (attempt %d): %v\n", row.IdempotencyKey, attempts, sendErr)
+	}
+
+	return true, nil
+}
+
+func (s *PostgresStore) mailboxMaxAttempts() int {
+	if s.cfg.MailboxMaxAttempts > 0 {
+		return s.cfg.MailboxMaxAttempts
+	}
+
+	return defaultMailboxMaxAttempts
+}
+
+// nextMailboxStatus is the status an outbox row moves to after its attempts-th
+// send: SENT on success, DRY_RUN when nothing was really sent, DEAD once
+// maxAttempts have failed, otherwise PENDING for another try.
+func nextMailboxStatus(sendErr error, attempts int, maxAttempts int) string {
+	switch {
+	case sendErr == nil:
+		return "SENT"
+	case errors.Is(sendErr, ErrMailboxDryRun):
+		return "DRY_RUN"
+	case attempts >= maxAttempts:
+		return "DEAD"
+	default:
+		return "PENDING"
+	}
+}
+
+// mailboxBackoff doubles the wait after every failed attempt, up to an hour.
+func mailboxBackoff(attempts int) time.Duration {
+	backoff := mailboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= mailboxMaxBackoff {
+			return mailboxMaxBackoff
+		}
+	}
+
+	return backoff
+}
+
+func (s *PostgresStore) GetMailboxOutbox(req *models.MailboxOutboxRequest) ([]*models.MailboxOutbox, error) {
+	status := req.Status
+	if status == "" {
+		status = "DEAD"
+	}
+
+	query := `SELECT ` + outboxColumns + ` FROM mailbox_outbox WHERE status = $1 AND ($2 = 0 OR robloxId = $2) ORDER BY id DESC LIMIT $3`
+
+	rows, err := s.db.Query(context.Background(), query, status, req.RobloxID, LIMIT)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	outbox := make([]*models.MailboxOutbox, 0)
+
+	for rows.Next() {
+		mail := &models.MailboxOutbox{}
+		if err := scanOutbox(rows, mail); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		outbox = append(outbox, mail)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return outbox, nil
+}
+
+// ReplayMailboxOutbox puts a dead-lettered or dry-run delivery back in the
+// queue with a fresh set of attempts. The idempotency key is kept so the
+// mailbox can drop it if an earlier attempt did land.
+func (s *PostgresStore) ReplayMailboxOutbox(req *models.MailboxOutboxRequest) (*models.MailboxOutbox, error) {
+	if req.ID == 0 {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	query := `
+	UPDATE mailbox_outbox SET status = 'PENDING', attempts = 0, nextAttempt = CURRENT_TIMESTAMP
+	WHERE id = $1 AND status IN ('DEAD', 'DRY_RUN')
+	RETURNING ` + outboxColumns
+
+	mail := &models.MailboxOutbox{}
+	err := scanOutbox(s.db.QueryRow(context.Background(), query, req.ID), mail)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("no dead-lettered or dry-run delivery with id %d", req.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	return mail, nil
+}
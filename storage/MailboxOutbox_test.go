@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kattah7/v3/models"
+)
+
+func TestMailboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{50, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := mailboxBackoff(tt.attempts); got != tt.want {
+			t.Errorf("mailboxBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestNextMailboxStatus(t *testing.T) {
+	failed := errors.New("mailbox unavailable")
+
+	tests := []struct {
+		name        string
+		sendErr     error
+		attempts    int
+		maxAttempts int
+		want        string
+	}{
+		{"sent first try", nil, 1, 3, "SENT"},
+		{"sent on last try", nil, 3, 3, "SENT"},
+		{"failed with tries left", failed, 1, 3, "PENDING"},
+		{"failed last try", failed, 3, 3, "DEAD"},
+		{"failed past max", failed, 4, 3, "DEAD"},
+		{"dry run", ErrMailboxDryRun, 1, 3, "DRY_RUN"},
+		{"dry run on last try", ErrMailboxDryRun, 3, 3, "DRY_RUN"},
+	}
+
+	for _, tt := range tests {
+		if got := nextMailboxStatus(tt.sendErr, tt.attempts, tt.maxAttempts); got != tt.want {
+			t.Errorf("%s: nextMailboxStatus = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryMailboxSenderDeadLetters(t *testing.T) {
+	sender := &MemoryMailboxSender{Err: errors.New("mailbox unavailable")}
+	mail := &models.MailboxExpire{RobloxId: 1, RobloxName: "seller"}
+	maxAttempts := 3
+
+	status := "PENDING"
+	for attempts := 1; status == "PENDING"; attempts++ {
+		status = nextMailboxStatus(sender.Send(context.Background(), mail, "auction:1:expired"), attempts, maxAttempts)
+
+		if attempts > maxAttempts {
+			t.Fatalf("still %s after %d attempts", status, attempts)
+		}
+	}
+
+	if status != "DEAD" {
+		t.Fatalf("status = %s, want DEAD", status)
+	}
+
+	if sent := sender.Sent(); len(sent) != 0 {
+		t.Fatalf("recorded %d deliveries for a failing sender", len(sent))
+	}
+}
+
+func TestMemoryMailboxSenderIdempotency(t *testing.T) {
+	sender := &MemoryMailboxSender{}
+	ctx := context.Background()
+
+	deliveries := []struct {
+		key  string
+		mail *models.MailboxExpire
+	}{
+		{"auction:1:won", &models.MailboxExpire{RobloxId: 1}},
+		{"auction:1:won", &models.MailboxExpire{RobloxId: 1}},
+		{"auction:2:expired", &models.MailboxExpire{RobloxId: 2}},
+	}
+
+	for _, d := range deliveries {
+		if err := sender.Send(ctx, d.mail, d.key); err != nil {
+			t.Fatalf("Send(%s) returned %v", d.key, err)
+		}
+	}
+
+	sent := sender.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("recorded %d deliveries, want 2", len(sent))
+	}
+
+	if sent[0].IdempotencyKey != "auction:1:won" || sent[1].IdempotencyKey != "auction:2:expired" {
+		t.Fatalf("recorded keys %s, %s", sent[0].IdempotencyKey, sent[1].IdempotencyKey)
+	}
+}
+
+func TestLogMailboxSenderDoesNotConsume(t *testing.T) {
+	sender := &LogMailboxSender{}
+	mail := &models.MailboxExpire{RobloxId: 1, RobloxName: "seller"}
+
+	status := nextMailboxStatus(sender.Send(context.Background(), mail, "auction:1:expired"), 1, 3)
+	if status != "DRY_RUN" {
+		t.Fatalf("status = %s, want DRY_RUN", status)
+	}
+}
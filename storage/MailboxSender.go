@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kattah7/v3/models"
+)
+
+const defaultMailboxTimeout = 10 * time.Second
+
+// MailboxSender delivers a single mailbox envelope. The outbox worker is the
+// only caller, so implementations don't need to retry.
+type MailboxSender interface {
+	Send(ctx context.Context, mail *models.MailboxExpire, idempotencyKey string) error
+}
+
+// ErrMailboxDryRun is returned by LogMailboxSender. The outbox parks such
+// rows as DRY_RUN instead of SENT, so they can be replayed once real
+// delivery is switched on.
+var ErrMailboxDryRun = errors.New("mailbox is in dry-run mode")
+
+// NewMailboxSender picks the sender named by mailboxMode: "http" (default)
+// or "dry-run". Tests inject a MemoryMailboxSender with SetMailboxSender.
+func NewMailboxSender(cfg *models.Config) (MailboxSender, error) {
+	switch cfg.MailboxMode {
+	case "", "http":
+		return NewHTTPMailboxSender(cfg), nil
+	case "dry-run":
+		return &LogMailboxSender{}, nil
+	default:
+		return nil, fmt.Errorf("mailboxMode must be http or dry-run")
+	}
+}
+
+type HTTPMailboxSender struct {
+	URL    string
+	Auth   string
+	Client *http.Client
+}
+
+func NewHTTPMailboxSender(cfg *models.Config) *HTTPMailboxSender {
+	url := cfg.MailboxURL
+	if url == "" {
+		if cfg.Prod {
+			url = "https://roblox.kattah.me/mailbox"
+		} else {
+			url = "https://playcrate-debug.kattah.me/mailbox"
+		}
+	}
+
+	auth := cfg.MailboxAuth
+	if auth == "" {
+		auth = cfg.V1Auth
+	}
+
+	timeout := defaultMailboxTimeout
+	if cfg.MailboxTimeout > 0 {
+		timeout = time.Duration(cfg.MailboxTimeout) * time.Second
+	}
+
+	return &HTTPMailboxSender{
+		URL:    url,
+		Auth:   auth,
+		Client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (m *HTTPMailboxSender) Send(ctx context.Context, mail *models.MailboxExpire, idempotencyKey string) error {
+	jsonData, err := json.Marshal(mail)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mailbox data: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", m.URL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	req.Header.Set("authorization", m.Auth)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send API request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read API response body: %w", err)
+	}
+
+	var apiResp models.ApiResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return fmt.Errorf("failed to unmarshal API response body: %w", err)
+	}
+
+	if !apiResp.Success {
+		return fmt.Errorf("mailbox rejected delivery: %s", apiResp.Message)
+	}
+
+	return nil
+}
+
+// LogMailboxSender logs deliveries instead of sending them, for running
+// against staging data without touching player mailboxes. Nothing is
+// delivered, so every send reports ErrMailboxDryRun.
+type LogMailboxSender struct{}
+
+func (m *LogMailboxSender) Send(ctx context.Context, mail *models.MailboxExpire, idempotencyKey string) error {
+	log.Printf("[dry-run] mailbox %s to %s (%d): %s\n", idempotencyKey, mail.RobloxName, mail.RobloxId, mail.Payload)
+	return ErrMailboxDryRun
+}
+
+type SentMailbox struct {
+	IdempotencyKey string
+	Mail           *models.MailboxExpire
+}
+
+// MemoryMailboxSender records deliveries so tests can inspect them. Like the
+// real mailbox it drops a key it has already delivered. Setting Err makes
+// every send fail with it.
+type MemoryMailboxSender struct {
+	mu   sync.Mutex
+	sent []SentMailbox
+	Err  error
+}
+
+func (m *MemoryMailboxSender) Send(ctx context.Context, mail *models.MailboxExpire, idempotencyKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return m.Err
+	}
+
+	for _, sent := range m.sent {
+		if sent.IdempotencyKey == idempotencyKey {
+			return nil
+		}
+	}
+
+	m.sent = append(m.sent, SentMailbox{IdempotencyKey: idempotencyKey, Mail: mail})
+	return nil
+}
+
+// Sent returns a copy of every delivery recorded so far.
+func (m *MemoryMailboxSender) Sent() []SentMailbox {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sent := make([]SentMailbox, len(m.sent))
+	copy(sent, m.sent)
+	return sent
+}
@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+// defaultMarketKeyFields are the itemData fields that identify "the same
+// item" for price history, e.g. a pet and whether it is shiny.
+var defaultMarketKeyFields = []string{"petId", "name", "shiny"}
+
+func (s *PostgresStore) marketKeyFields() []string {
+	if len(s.cfg.MarketKeyFields) > 0 {
+		return s.cfg.MarketKeyFields
+	}
+
+	return defaultMarketKeyFields
+}
+
+// maxMarketKeyLength is the size of auction_sales.itemKey.
+const maxMarketKeyLength = 255
+
+// marketKey builds the item key from the configured itemData fields, in
+// configured order, skipping any the item does not have. The fields come from
+// the seller, so a key too long for the column is replaced by its hash.
+func (s *PostgresStore) marketKey(itemData map[string]interface{}) string {
+	parts := make([]string, 0)
+	for _, field := range s.marketKeyFields() {
+		if value, ok := itemData[field]; ok && value != nil {
+			parts = append(parts, fmt.Sprintf("%s=%v", field, value))
+		}
+	}
+
+	key := strings.Join(parts, ";")
+	if len(key) > maxMarketKeyLength {
+		sum := sha256.Sum256([]byte(key))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	return key
+}
+
+// recordSale copies a completed sale into auction_sales, which keeps the
+// price history after the listing itself is claimed or removed.
+func (s *PostgresStore) recordSale(ctx context.Context, tx pgx.Tx, uid int64) error {
+	query := `SELECT itemType, itemData, priceType, soldPrice, robloxId, buyerId, purchased FROM auctions WHERE id = $1`
+
+	return s.insertSale(ctx, tx, tx.QueryRow(ctx, query, uid), uid)
+}
+
+func (s *PostgresStore) insertSale(ctx context.Context, tx pgx.Tx, row pgx.Row, uid int64) error {
+	var itemType, priceType string
+	var itemData map[string]interface{}
+	var price, sellerId, buyerId int64
+	var sold *time.Time
+
+	if err := row.Scan(&itemType, &itemData, &priceType, &price, &sellerId, &buyerId, &sold); err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	query := `
+	INSERT INTO auction_sales (auctionId, itemType, itemKey, priceType, price, sellerId, buyerId, sold)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, CURRENT_TIMESTAMP))
+	ON CONFLICT (auctionId) DO NOTHING
+	`
+
+	if _, err := tx.Exec(ctx, query, uid, itemType, s.marketKey(itemData), priceType, price, sellerId, buyerId, sold); err != nil {
+		return fmt.Errorf("unable to insert sale: %w", err)
+	}
+
+	return nil
+}
+
+// backfillSales records sales that completed before auction_sales existed.
+func (s *PostgresStore) backfillSales() error {
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `SELECT id FROM auctions WHERE soldPrice > 0 AND id NOT IN (SELECT auctionId FROM auction_sales)`)
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := s.recordSale(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetMarketStats returns sale statistics for itemType. With itemData set only
+// the matching item key is returned; otherwise every key of the type is, most
+// traded first. priceType narrows the result to one currency.
+func (s *PostgresStore) GetMarketStats(item *models.AuctionAccount) ([]*models.MarketStats, error) {
+	if item.ItemType == "" {
+		return nil, fmt.Errorf("itemType cannot be empty")
+	}
+
+	itemKey := ""
+	if len(item.ItemData) > 0 && string(item.ItemData) != "null" {
+		var itemData map[string]interface{}
+		if err := json.Unmarshal(item.ItemData, &itemData); err != nil {
+			return nil, fmt.Errorf("itemData must be a JSON object")
+		}
+
+		itemKey = s.marketKey(itemData)
+	}
+
+	query := `
+	SELECT itemKey, priceType,
+		(array_agg(price ORDER BY sold DESC))[1], MAX(sold),
+		COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY price) FILTER (WHERE sold > CURRENT_TIMESTAMP - INTERVAL '30 days'), 0)::BIGINT,
+		COALESCE(MIN(price) FILTER (WHERE sold > CURRENT_TIMESTAMP - INTERVAL '30 days'), 0),
+		COALESCE(MAX(price) FILTER (WHERE sold > CURRENT_TIMESTAMP - INTERVAL '30 days'), 0),
+		COUNT(*) FILTER (WHERE sold > CURRENT_TIMESTAMP - INTERVAL '1 day'),
+		COUNT(*) FILTER (WHERE sold > CURRENT_TIMESTAMP - INTERVAL '7 days'),
+		COUNT(*) FILTER (WHERE sold > CURRENT_TIMESTAMP - INTERVAL '30 days')
+	FROM auction_sales
+	WHERE itemType = $1 AND ($2 = '' OR itemKey = $2) AND ($3 = '' OR priceType = $3)
+	GROUP BY itemKey, priceType
+	ORDER BY 10 DESC, itemKey, priceType
+	LIMIT $4
+	`
+
+	rows, err := s.db.Query(context.Background(), query, item.ItemType, itemKey, item.PriceType, LIMIT)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	stats := make([]*models.MarketStats, 0)
+	byKey := make(map[string]*models.MarketStats)
+	keys := make([]string, 0)
+
+	for rows.Next() {
+		stat := &models.MarketStats{ItemType: item.ItemType, Series: make([]*models.MarketDay, 0)}
+		if err := rows.Scan(&stat.ItemKey, &stat.PriceType, &stat.LastSale, &stat.LastSold, &stat.Median, &stat.Min, &stat.Max, &stat.Volume.Day, &stat.Volume.Week, &stat.Volume.Month); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		stats = append(stats, stat)
+		byKey[stat.ItemKey+"|"+stat.PriceType] = stat
+		keys = append(keys, stat.ItemKey)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	if len(stats) == 0 {
+		return stats, nil
+	}
+
+	seriesQuery := `
+	SELECT itemKey, priceType, date_trunc('day', sold), COUNT(*),
+		percentile_cont(0.5) WITHIN GROUP (ORDER BY price)::BIGINT, MIN(price), MAX(price)
+	FROM auction_sales
+	WHERE itemType = $1 AND itemKey = ANY($2) AND ($3 = '' OR priceType = $3) AND sold > CURRENT_TIMESTAMP - INTERVAL '30 days'
+	GROUP BY 1, 2, 3
+	ORDER BY 3
+	`
+
+	seriesRows, err := s.db.Query(context.Background(), seriesQuery, item.ItemType, keys, item.PriceType)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer seriesRows.Close()
+
+	for seriesRows.Next() {
+		var itemKey, priceType string
+		day := &models.MarketDay{}
+		if err := seriesRows.Scan(&itemKey, &priceType, &day.Day, &day.Sales, &day.Median, &day.Min, &day.Max); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		if stat, ok := byKey[itemKey+"|"+priceType]; ok {
+			stat.Series = append(stat.Series, day)
+		}
+	}
+
+	if err := seriesRows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kattah7/v3/models"
+)
+
+func TestMarketKey(t *testing.T) {
+	s := &PostgresStore{cfg: &models.Config{}}
+
+	tests := []struct {
+		name     string
+		itemData map[string]interface{}
+		want     string
+	}{
+		{"all fields", map[string]interface{}{"petId": 3.0, "name": "Dog", "shiny": true, "level": 5.0}, "petId=3;name=Dog;shiny=true"},
+		{"missing fields", map[string]interface{}{"name": "Dog"}, "name=Dog"},
+		{"null field", map[string]interface{}{"name": "Dog", "shiny": nil}, "name=Dog"},
+		{"empty", map[string]interface{}{}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := s.marketKey(tt.itemData); got != tt.want {
+			t.Errorf("%s: marketKey = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMarketKeyHashesLongKeys(t *testing.T) {
+	s := &PostgresStore{cfg: &models.Config{}}
+
+	long := map[string]interface{}{"name": strings.Repeat("x", 1000)}
+	key := s.marketKey(long)
+
+	if len(key) > maxMarketKeyLength || !strings.HasPrefix(key, "sha256:") {
+		t.Fatalf("marketKey = %q, want a sha256 key within %d characters", key, maxMarketKeyLength)
+	}
+
+	if s.marketKey(long) != key {
+		t.Fatal("marketKey is not stable for the same item")
+	}
+
+	other := map[string]interface{}{"name": strings.Repeat("y", 1000)}
+	if s.marketKey(other) == key {
+		t.Fatal("different long keys hash to the same key")
+	}
+}
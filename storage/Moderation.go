@@ -0,0 +1,345 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+var banScopes = map[string]bool{"LIST": true, "BUY": true, "ALL": true}
+
+// queryRower is satisfied by both the pool and a transaction.
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+const banColumns = `id, robloxId, scope, reason, moderator, until, created, lifted, liftedBy, liftReason`
+
+func scanBan(row pgx.Row, ban *models.AuctionBan) error {
+	return row.Scan(&ban.ID, &ban.RobloxID, &ban.Scope, &ban.Reason, &ban.Moderator, &ban.Until, &ban.Created, &ban.Lifted, &ban.LiftedBy, &ban.LiftReason)
+}
+
+// checkBan rejects a player with an active ban covering action, which is
+// LIST or BUY.
+func checkBan(ctx context.Context, q queryRower, robloxId int64, action string) error {
+	query := `
+	SELECT until FROM auction_bans
+	WHERE robloxId = $1 AND scope IN ($2, 'ALL') AND lifted IS NULL AND (until IS NULL OR until > CURRENT_TIMESTAMP)
+	ORDER BY until DESC NULLS FIRST
+	LIMIT 1
+	`
+
+	var until *time.Time
+	err := q.QueryRow(ctx, query, robloxId, action).Scan(&until)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	verb := "listing"
+	if action == "BUY" {
+		verb = "buying"
+	}
+
+	if until == nil {
+		return fmt.Errorf("robloxId is banned from %s in the auction house", verb)
+	}
+
+	return fmt.Errorf("robloxId is banned from %s in the auction house until %s", verb, until.UTC().Format(time.RFC3339))
+}
+
+func checkModeration(req *models.ModerationRequest) error {
+	if req.Reason == "" {
+		return fmt.Errorf("reason cannot be empty")
+	}
+
+	if req.Moderator == "" {
+		return fmt.Errorf("moderator cannot be empty")
+	}
+
+	return nil
+}
+
+func recordModeration(ctx context.Context, tx pgx.Tx, action string, robloxId int64, auctionId int64, req *models.ModerationRequest) error {
+	query := `
+	INSERT INTO auction_moderation (action, robloxId, auctionId, reason, moderator)
+	VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), $4, $5)
+	`
+
+	if _, err := tx.Exec(ctx, query, action, robloxId, auctionId, req.Reason, req.Moderator); err != nil {
+		return fmt.Errorf("unable to insert moderation row: %w", err)
+	}
+
+	return nil
+}
+
+// BanPlayer keeps robloxId out of the auction house for scope (LIST, BUY or
+// ALL). seconds of 0 bans permanently.
+func (s *PostgresStore) BanPlayer(req *models.ModerationRequest) (*models.AuctionBan, error) {
+	if err := checkModeration(req); err != nil {
+		return nil, err
+	}
+
+	if req.RobloxID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	if !banScopes[req.Scope] {
+		return nil, fmt.Errorf("scope must be LIST, BUY, or ALL")
+	}
+
+	if req.Seconds < 0 {
+		return nil, fmt.Errorf("seconds cannot be negative")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO auction_bans (robloxId, scope, reason, moderator, until)
+	VALUES ($1, $2, $3, $4, CASE WHEN $5 > 0 THEN CURRENT_TIMESTAMP + make_interval(secs => $5) END)
+	RETURNING ` + banColumns
+
+	ban := &models.AuctionBan{}
+	if err := scanBan(tx.QueryRow(ctx, query, req.RobloxID, req.Scope, req.Reason, req.Moderator, req.Seconds), ban); err != nil {
+		return nil, fmt.Errorf("unable to insert row: %w", err)
+	}
+
+	if err := recordModeration(ctx, tx, "BAN_"+req.Scope, req.RobloxID, 0, req); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return ban, nil
+}
+
+// UnbanPlayer lifts the ban with the given id before it runs out.
+func (s *PostgresStore) UnbanPlayer(req *models.ModerationRequest) (*models.AuctionBan, error) {
+	if err := checkModeration(req); err != nil {
+		return nil, err
+	}
+
+	if req.ID == 0 {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	UPDATE auction_bans SET lifted = CURRENT_TIMESTAMP, liftedBy = $2, liftReason = $3
+	WHERE id = $1 AND lifted IS NULL
+	RETURNING ` + banColumns
+
+	ban := &models.AuctionBan{}
+	err = scanBan(tx.QueryRow(ctx, query, req.ID, req.Moderator, req.Reason), ban)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("no active ban with id %d", req.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	if err := recordModeration(ctx, tx, "UNBAN", ban.RobloxID, 0, req); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return ban, nil
+}
+
+// GetBans lists bans that are still in force, for one player or everyone.
+func (s *PostgresStore) GetBans(req *models.ModerationRequest) ([]*models.AuctionBan, error) {
+	query := `
+	SELECT ` + banColumns + ` FROM auction_bans
+	WHERE ($1 = 0 OR robloxId = $1) AND lifted IS NULL AND (until IS NULL OR until > CURRENT_TIMESTAMP)
+	ORDER BY id DESC
+	LIMIT $2
+	`
+
+	rows, err := s.db.Query(context.Background(), query, req.RobloxID, LIMIT)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	bans := make([]*models.AuctionBan, 0)
+
+	for rows.Next() {
+		ban := &models.AuctionBan{}
+		if err := scanBan(rows, ban); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		bans = append(bans, ban)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return bans, nil
+}
+
+// HideAuction takes an OPEN listing out of browsing and buying while it is
+// reviewed. Hidden listings do not expire until they are shown again.
+func (s *PostgresStore) HideAuction(req *models.ModerationRequest) error {
+	return s.setAuctionHidden(req, true, "HIDDEN")
+}
+
+func (s *PostgresStore) UnhideAuction(req *models.ModerationRequest) error {
+	return s.setAuctionHidden(req, false, "UNHIDDEN")
+}
+
+func (s *PostgresStore) setAuctionHidden(req *models.ModerationRequest, hidden bool, event string) error {
+	if err := checkModeration(req); err != nil {
+		return err
+	}
+
+	if req.ID == 0 {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var sellerId int64
+	query := `UPDATE auctions SET hidden = $2 WHERE id = $1 AND status = 'OPEN' AND hidden <> $2 RETURNING robloxId`
+	err = tx.QueryRow(ctx, query, req.ID, hidden).Scan(&sellerId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("no OPEN listing with id %d to change", req.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	if err := recordAuctionEvent(ctx, tx, req.ID, event, 0, req.Moderator, req.Reason); err != nil {
+		return err
+	}
+
+	if err := recordModeration(ctx, tx, event, sellerId, req.ID, req); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ModerateRemoveAuction closes an OPEN listing, hidden or not, and returns
+// the item to the seller through the mailbox. The row is kept with status
+// REMOVED.
+func (s *PostgresStore) ModerateRemoveAuction(req *models.ModerationRequest) error {
+	if err := checkModeration(req); err != nil {
+		return err
+	}
+
+	if req.ID == 0 {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var sellerId int64
+	var sellerName string
+	var itemData map[string]interface{}
+
+	err = tx.QueryRow(ctx, `SELECT robloxId, robloxName, itemData FROM auctions WHERE id = $1 AND status = 'OPEN' FOR UPDATE`, req.ID).Scan(&sellerId, &sellerName, &itemData)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("no OPEN listing with id %d", req.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	removeQuery := `UPDATE auctions SET status = 'REMOVED', hidden = false, reservedBy = 0, reservedUntil = NULL WHERE id = $1`
+	if err := closeAuctionTx(ctx, tx, req.ID, removeQuery, "REMOVED_BY_ADMIN", 0, req.Moderator, req.Reason); err != nil {
+		return err
+	}
+
+	mail, err := newMailbox(sellerId, sellerName, "Your listing was removed by a moderator and the item has been returned to your mailbox.", itemData)
+	if err != nil {
+		return err
+	}
+
+	if err := enqueueMailbox(ctx, tx, fmt.Sprintf("auction:%d:removed", req.ID), mail); err != nil {
+		return err
+	}
+
+	if err := recordModeration(ctx, tx, "REMOVE", sellerId, req.ID, req); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetModerationActions returns the moderation log, newest first, for one
+// player or everyone.
+func (s *PostgresStore) GetModerationActions(req *models.ModerationRequest) ([]*models.ModerationAction, error) {
+	query := `
+	SELECT id, action, COALESCE(robloxId, 0), COALESCE(auctionId, 0), reason, moderator, created FROM auction_moderation
+	WHERE ($1 = 0 OR robloxId = $1)
+	ORDER BY id DESC
+	LIMIT $2
+	`
+
+	rows, err := s.db.Query(context.Background(), query, req.RobloxID, LIMIT)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	actions := make([]*models.ModerationAction, 0)
+
+	for rows.Next() {
+		action := &models.ModerationAction{}
+		if err := rows.Scan(&action.ID, &action.Action, &action.RobloxID, &action.AuctionID, &action.Reason, &action.Moderator, &action.Created); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		actions = append(actions, action)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return actions, nil
+}
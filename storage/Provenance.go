@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+const defaultProvenanceField = "uuid"
+
+// provenanceField is the itemData field holding an item's unique id. Items
+// without it are not tracked.
+func (s *PostgresStore) provenanceField() string {
+	if s.cfg.ProvenanceField != "" {
+		return s.cfg.ProvenanceField
+	}
+
+	return defaultProvenanceField
+}
+
+// recordProvenance appends the listing's item to its ownership chain with
+// ownerId as the owner after event.
+func (s *PostgresStore) recordProvenance(ctx context.Context, tx pgx.Tx, uid int64, event string, ownerId int64) error {
+	query := `
+	INSERT INTO item_provenance (itemUuid, auctionId, event, ownerId)
+	SELECT itemData->>$2, id, $3, $4 FROM auctions
+	WHERE id = $1 AND COALESCE(itemData->>$2, '') <> ''
+	`
+
+	if _, err := tx.Exec(ctx, query, uid, s.provenanceField(), event, ownerId); err != nil {
+		return fmt.Errorf("unable to insert provenance row: %w", err)
+	}
+
+	return nil
+}
+
+// recordTradeProvenance appends every item in a completed trade bundle to its
+// ownership chain with ownerId, the player receiving it, as the new owner.
+// Trades have no listing, so auctionId is 0.
+func (s *PostgresStore) recordTradeProvenance(ctx context.Context, tx pgx.Tx, ownerId int64, bundle *models.TradeBundle) error {
+	query := `
+	INSERT INTO item_provenance (itemUuid, auctionId, event, ownerId)
+	SELECT $1::jsonb->>$2, 0, 'TRADED', $3
+	WHERE COALESCE($1::jsonb->>$2, '') <> ''
+	`
+
+	for _, item := range bundle.Items {
+		if _, err := tx.Exec(ctx, query, string(item.ItemData), s.provenanceField(), ownerId); err != nil {
+			return fmt.Errorf("unable to insert provenance row: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkProvenance flags a new listing whose item is already in another OPEN
+// listing, or whose seller is not the item's last recorded owner. It then
+// records the listing in the chain. Flagged listings are not blocked.
+func (s *PostgresStore) checkProvenance(ctx context.Context, tx pgx.Tx, uid int64, sellerId int64) error {
+	field := s.provenanceField()
+
+	var itemUuid string
+	err := tx.QueryRow(ctx, `SELECT COALESCE(itemData->>$2, '') FROM auctions WHERE id = $1`, uid, field).Scan(&itemUuid)
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	if itemUuid == "" {
+		return nil
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id, robloxId FROM auctions WHERE status = 'OPEN' AND id <> $1 AND itemData->>$2 = $3`, uid, field, itemUuid)
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	type listing struct {
+		AuctionID int64 `json:"auctionId"`
+		SellerID  int64 `json:"sellerId"`
+	}
+
+	var duplicates []listing
+	for rows.Next() {
+		var other listing
+		if err := rows.Scan(&other.AuctionID, &other.SellerID); err != nil {
+			rows.Close()
+			return fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		duplicates = append(duplicates, other)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	if len(duplicates) > 0 {
+		evidence := map[string]interface{}{"sellerId": sellerId, "openListings": duplicates}
+		if err := raiseFlag(ctx, tx, fmt.Sprintf("DUPLICATE_LISTING:%d", uid), "DUPLICATE_LISTING", itemUuid, uid, sellerId, evidence); err != nil {
+			return err
+		}
+	}
+
+	var lastOwner, lastAuction int64
+	var lastEvent string
+	err = tx.QueryRow(ctx, `SELECT ownerId, auctionId, event FROM item_provenance WHERE itemUuid = $1 ORDER BY id DESC LIMIT 1`, itemUuid).Scan(&lastOwner, &lastAuction, &lastEvent)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	if err == nil && lastOwner != sellerId {
+		evidence := map[string]interface{}{"sellerId": sellerId, "lastOwnerId": lastOwner, "lastAuctionId": lastAuction, "lastEvent": lastEvent}
+		if err := raiseFlag(ctx, tx, fmt.Sprintf("OWNER_MISMATCH:%d", uid), "OWNER_MISMATCH", itemUuid, uid, sellerId, evidence); err != nil {
+			return err
+		}
+	}
+
+	return s.recordProvenance(ctx, tx, uid, "LISTED", sellerId)
+}
+
+// GetProvenance returns the ownership chain of one item, oldest first.
+func (s *PostgresStore) GetProvenance(req *models.FlagRequest) ([]*models.ProvenanceEntry, error) {
+	if req.ItemUUID == "" {
+		return nil, fmt.Errorf("itemUuid cannot be empty")
+	}
+
+	query := `SELECT id, itemUuid, auctionId, event, ownerId, created FROM item_provenance WHERE itemUuid = $1 ORDER BY id`
+
+	rows, err := s.db.Query(context.Background(), query, req.ItemUUID)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	chain := make([]*models.ProvenanceEntry, 0)
+
+	for rows.Next() {
+		entry := &models.ProvenanceEntry{}
+		if err := rows.Scan(&entry.ID, &entry.ItemUUID, &entry.AuctionID, &entry.Event, &entry.OwnerID, &entry.Created); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		chain = append(chain, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return chain, nil
+}
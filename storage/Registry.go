@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+// Seeded on first start so existing listings keep working.
+var (
+	defaultItemTypes  = []string{"EGG", "PET", "BOOST", "POTION"}
+	defaultPriceTypes = []string{"Diamonds", "Coins", "DarkCoins", "Pearls", "Candy", "Chocolate"}
+)
+
+// typeRegistry caches the active item and price types, and every price type
+// ever registered. It is reloaded after every admin change and once a minute
+// for changes made by other instances.
+type typeRegistry struct {
+	mu              sync.RWMutex
+	itemTypes       map[string]*itemSchema
+	itemNames       []string
+	priceTypes      []string
+	knownPriceTypes map[string]bool
+}
+
+func (s *PostgresStore) seedRegistry() error {
+	for _, name := range defaultItemTypes {
+		if _, err := s.db.Exec(context.Background(), `INSERT INTO item_types (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`, name); err != nil {
+			return fmt.Errorf("unable to insert row: %w", err)
+		}
+	}
+
+	for _, name := range defaultPriceTypes {
+		if _, err := s.db.Exec(context.Background(), `INSERT INTO price_types (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`, name); err != nil {
+			return fmt.Errorf("unable to insert row: %w", err)
+		}
+	}
+
+	return s.loadRegistry()
+}
+
+func (s *PostgresStore) loadRegistry() error {
+	registry, err := s.GetRegistry()
+	if err != nil {
+		return err
+	}
+
+	itemTypes := make(map[string]*itemSchema)
+	itemNames := make([]string, 0)
+	for _, entry := range registry.ItemTypes {
+		if !entry.Active {
+			continue
+		}
+
+		schema, err := parseItemSchema(entry.Schema)
+		if err != nil {
+			return fmt.Errorf("item type %s: %w", entry.Name, err)
+		}
+
+		itemTypes[entry.Name] = schema
+		itemNames = append(itemNames, entry.Name)
+	}
+
+	priceTypes := make([]string, 0)
+	knownPriceTypes := make(map[string]bool)
+	for _, entry := range registry.PriceTypes {
+		knownPriceTypes[entry.Name] = true
+		if entry.Active {
+			priceTypes = append(priceTypes, entry.Name)
+		}
+	}
+
+	s.registry.mu.Lock()
+	s.registry.itemTypes = itemTypes
+	s.registry.itemNames = itemNames
+	s.registry.priceTypes = priceTypes
+	s.registry.knownPriceTypes = knownPriceTypes
+	s.registry.mu.Unlock()
+
+	return nil
+}
+
+// checkItem rejects unknown item types and itemData that does not match the
+// type's schema.
+func (s *PostgresStore) checkItem(itemType string, itemData []byte) error {
+	s.registry.mu.RLock()
+	schema, ok := s.registry.itemTypes[itemType]
+	names := s.registry.itemNames
+	s.registry.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("itemType must be one of %s", strings.Join(names, ", "))
+	}
+
+	return schema.validate(itemData)
+}
+
+func (s *PostgresStore) checkPriceType(priceType string) error {
+	s.registry.mu.RLock()
+	defer s.registry.mu.RUnlock()
+
+	for _, v := range s.registry.priceTypes {
+		if v == priceType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("priceType must be one of %s", strings.Join(s.registry.priceTypes, ", "))
+}
+
+// checkKnownPriceType accepts removed price types too, so players can still
+// take out balances held in a currency that is no longer traded.
+func (s *PostgresStore) checkKnownPriceType(priceType string) error {
+	s.registry.mu.RLock()
+	known := s.registry.knownPriceTypes[priceType]
+	s.registry.mu.RUnlock()
+
+	if !known {
+		return s.checkPriceType(priceType)
+	}
+
+	return nil
+}
+
+// GetRegistry returns every item and price type, including inactive ones.
+func (s *PostgresStore) GetRegistry() (*models.Registry, error) {
+	registry := &models.Registry{
+		ItemTypes:  make([]*models.ItemTypeEntry, 0),
+		PriceTypes: make([]*models.PriceTypeEntry, 0),
+	}
+
+	rows, err := s.db.Query(context.Background(), `SELECT name, schema, active, updated FROM item_types ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	for rows.Next() {
+		entry := &models.ItemTypeEntry{}
+		if err := rows.Scan(&entry.Name, &entry.Schema, &entry.Active, &entry.Updated); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		registry.ItemTypes = append(registry.ItemTypes, entry)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	rows, err = s.db.Query(context.Background(), `SELECT name, active, updated FROM price_types ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := &models.PriceTypeEntry{}
+		if err := rows.Scan(&entry.Name, &entry.Active, &entry.Updated); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		registry.PriceTypes = append(registry.PriceTypes, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return registry, nil
+}
+
+// SetItemType adds an item type or replaces its schema, and activates it.
+func (s *PostgresStore) SetItemType(req *models.RegistryRequest) (*models.ItemTypeEntry, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("name cannot be empty")
+	}
+
+	if err := checkSchemaKeywords(req.Schema); err != nil {
+		return nil, err
+	}
+
+	if _, err := parseItemSchema(req.Schema); err != nil {
+		return nil, err
+	}
+
+	schema := req.Schema
+	if len(schema) == 0 || string(schema) == "null" {
+		schema = []byte(`{"type": "object"}`)
+	}
+
+	query := `
+	INSERT INTO item_types (name, schema) VALUES ($1, $2)
+	ON CONFLICT (name) DO UPDATE SET schema = EXCLUDED.schema, active = true, updated = CURRENT_TIMESTAMP
+	RETURNING name, schema, active, updated
+	`
+
+	entry := &models.ItemTypeEntry{}
+	if err := s.db.QueryRow(context.Background(), query, req.Name, schema).Scan(&entry.Name, &entry.Schema, &entry.Active, &entry.Updated); err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	return entry, s.loadRegistry()
+}
+
+// SetPriceType adds a price type, or reactivates a removed one.
+func (s *PostgresStore) SetPriceType(req *models.RegistryRequest) (*models.PriceTypeEntry, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("name cannot be empty")
+	}
+
+	query := `
+	INSERT INTO price_types (name) VALUES ($1)
+	ON CONFLICT (name) DO UPDATE SET active = true, updated = CURRENT_TIMESTAMP
+	RETURNING name, active, updated
+	`
+
+	entry := &models.PriceTypeEntry{}
+	if err := s.db.QueryRow(context.Background(), query, req.Name).Scan(&entry.Name, &entry.Active, &entry.Updated); err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	return entry, s.loadRegistry()
+}
+
+// RemoveItemType stops new listings of a type. Existing listings are kept.
+func (s *PostgresStore) RemoveItemType(req *models.RegistryRequest) error {
+	return s.deactivateType(`UPDATE item_types SET active = false, updated = CURRENT_TIMESTAMP WHERE name = $1 AND active RETURNING name`, req.Name)
+}
+
+// RemovePriceType stops new listings and deposits in a currency. Existing
+// listings are kept and balances can still be withdrawn.
+func (s *PostgresStore) RemovePriceType(req *models.RegistryRequest) error {
+	return s.deactivateType(`UPDATE price_types SET active = false, updated = CURRENT_TIMESTAMP WHERE name = $1 AND active RETURNING name`, req.Name)
+}
+
+func (s *PostgresStore) deactivateType(query string, name string) error {
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+
+	err := s.db.QueryRow(context.Background(), query, name).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s is not an active type", name)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	return s.loadRegistry()
+}
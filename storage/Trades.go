@@ -0,0 +1,424 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+const (
+	maxTradeItems       = 10
+	defaultTradeSeconds = 3 * 24 * 60 * 60
+)
+
+// tradeSeconds is how long a trade can wait for an answer before it expires
+// and the offer is returned to the sender.
+func (s *PostgresStore) tradeSeconds() int64 {
+	if s.cfg.TradeSeconds > 0 {
+		return s.cfg.TradeSeconds
+	}
+
+	return defaultTradeSeconds
+}
+
+const tradeColumns = `id, senderId, senderName, targetId, targetName, offered, requested, status, created, responded`
+
+func scanTrade(row pgx.Row, trade *models.Trade) error {
+	return row.Scan(&trade.ID, &trade.SenderID, &trade.SenderName, &trade.TargetID, &trade.TargetName, &trade.Offered, &trade.Requested, &trade.Status, &trade.Created, &trade.Responded)
+}
+
+func (s *PostgresStore) validateTradeBundle(side string, bundle *models.TradeBundle) error {
+	if len(bundle.Items) > maxTradeItems {
+		return fmt.Errorf("%s cannot hold more than %d items", side, maxTradeItems)
+	}
+
+	for _, item := range bundle.Items {
+		if item.ItemType == "" {
+			return fmt.Errorf("%s items must have an itemType", side)
+		}
+
+		var itemData map[string]interface{}
+		if err := json.Unmarshal(item.ItemData, &itemData); err != nil || itemData == nil {
+			return fmt.Errorf("%s items must have itemData as a JSON object", side)
+		}
+
+		if err := s.checkItem(item.ItemType, item.ItemData); err != nil {
+			return fmt.Errorf("%s: %w", side, err)
+		}
+	}
+
+	if bundle.Amount < 0 {
+		return fmt.Errorf("%s amount cannot be negative", side)
+	}
+
+	if bundle.Amount > 0 {
+		if err := s.checkPriceType(bundle.PriceType); err != nil {
+			return err
+		}
+	}
+
+	if bundle.Items == nil {
+		bundle.Items = []models.TradeItem{}
+	}
+
+	return nil
+}
+
+// ProposeTrade records a trade from the sender to the target. The game server
+// removes the offered items from the sender before calling this, and the
+// offered currency is held in escrow until the trade is answered or expires.
+func (s *PostgresStore) ProposeTrade(trade *models.Trade) (*models.Trade, error) {
+	if trade.RobloxID == 0 || trade.RobloxName == "" {
+		return nil, fmt.Errorf("robloxId and robloxName cannot be empty")
+	}
+
+	if trade.TargetID == 0 || trade.TargetName == "" {
+		return nil, fmt.Errorf("targetId and targetName cannot be empty")
+	}
+
+	if trade.RobloxID == trade.TargetID {
+		return nil, fmt.Errorf("cannot trade with yourself")
+	}
+
+	if err := s.validateTradeBundle("offered", &trade.Offered); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateTradeBundle("requested", &trade.Requested); err != nil {
+		return nil, err
+	}
+
+	if len(trade.Offered.Items) == 0 && trade.Offered.Amount == 0 {
+		return nil, fmt.Errorf("offered must contain at least one item or an amount")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO trades (senderId, senderName, targetId, targetName, offered, requested)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING ` + tradeColumns
+
+	created := &models.Trade{}
+	if err := scanTrade(tx.QueryRow(ctx, query, trade.RobloxID, trade.RobloxName, trade.TargetID, trade.TargetName, trade.Offered, trade.Requested), created); err != nil {
+		return nil, fmt.Errorf("unable to insert row: %w", err)
+	}
+
+	if created.Offered.Amount > 0 {
+		if _, err := adjustWallet(ctx, tx, created.SenderID, created.Offered.PriceType, -created.Offered.Amount, "TRADE_HOLD", 0, fmt.Sprintf("trade:%d:hold", created.ID)); err != nil {
+			return nil, err
+		}
+
+		if _, err := adjustWallet(ctx, tx, escrowAccount, created.Offered.PriceType, created.Offered.Amount, "ESCROW_HOLD", 0, fmt.Sprintf("trade:%d:escrow", created.ID)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return created, nil
+}
+
+// lockTrade loads a PENDING trade for update.
+func lockTrade(ctx context.Context, tx pgx.Tx, id int64) (*models.Trade, error) {
+	query := `SELECT ` + tradeColumns + ` FROM trades WHERE id = $1 AND status = 'PENDING' FOR UPDATE`
+
+	trade := &models.Trade{}
+	err := scanTrade(tx.QueryRow(ctx, query, id), trade)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("trade is not pending")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	return trade, nil
+}
+
+// AcceptTrade completes a trade. The game server removes the requested items
+// from the target before calling this. Currency moves between wallets: the
+// requested amount from the target to the sender, and the offered amount out
+// of escrow to the target. Items are delivered to the other side through the
+// mailbox and recorded in their provenance chains under their new owner.
+func (s *PostgresStore) AcceptTrade(req *models.Trade) (*models.Trade, error) {
+	if req.ID == 0 {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	trade, err := lockTrade(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if trade.TargetID != req.RobloxID {
+		return nil, fmt.Errorf("only the target can accept a trade")
+	}
+
+	if trade.Requested.Amount > 0 {
+		if _, err := adjustWallet(ctx, tx, trade.TargetID, trade.Requested.PriceType, -trade.Requested.Amount, "TRADE_PAYMENT", 0, fmt.Sprintf("trade:%d:payment", trade.ID)); err != nil {
+			return nil, err
+		}
+
+		if _, err := adjustWallet(ctx, tx, trade.SenderID, trade.Requested.PriceType, trade.Requested.Amount, "TRADE_RECEIVED", 0, fmt.Sprintf("trade:%d:paid", trade.ID)); err != nil {
+			return nil, err
+		}
+	}
+
+	if trade.Offered.Amount > 0 {
+		if _, err := adjustWallet(ctx, tx, escrowAccount, trade.Offered.PriceType, -trade.Offered.Amount, "ESCROW_RELEASE", 0, fmt.Sprintf("trade:%d:release", trade.ID)); err != nil {
+			return nil, err
+		}
+
+		if _, err := adjustWallet(ctx, tx, trade.TargetID, trade.Offered.PriceType, trade.Offered.Amount, "TRADE_RECEIVED", 0, fmt.Sprintf("trade:%d:received", trade.ID)); err != nil {
+			return nil, err
+		}
+	}
+
+	message := fmt.Sprintf("Your trade with %s was completed.", trade.SenderName)
+	if err := deliverTradeItems(ctx, tx, fmt.Sprintf("trade:%d:target", trade.ID), trade.TargetID, trade.TargetName, message, trade.Offered.Items); err != nil {
+		return nil, err
+	}
+
+	message = fmt.Sprintf("Your trade with %s was completed.", trade.TargetName)
+	if err := deliverTradeItems(ctx, tx, fmt.Sprintf("trade:%d:sender", trade.ID), trade.SenderID, trade.SenderName, message, trade.Requested.Items); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordTradeProvenance(ctx, tx, trade.TargetID, &trade.Offered); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordTradeProvenance(ctx, tx, trade.SenderID, &trade.Requested); err != nil {
+		return nil, err
+	}
+
+	accepted, err := closeTrade(ctx, tx, trade.ID, "ACCEPTED")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return accepted, nil
+}
+
+// DeclineTrade lets the target turn a trade down.
+func (s *PostgresStore) DeclineTrade(req *models.Trade) (*models.Trade, error) {
+	return s.returnTrade(req, "DECLINED", func(trade *models.Trade) bool {
+		return trade.TargetID == req.RobloxID
+	})
+}
+
+// CancelTrade lets the sender withdraw a trade that has not been answered.
+func (s *PostgresStore) CancelTrade(req *models.Trade) (*models.Trade, error) {
+	return s.returnTrade(req, "CANCELLED", func(trade *models.Trade) bool {
+		return trade.SenderID == req.RobloxID
+	})
+}
+
+// returnTrade closes a pending trade without completing it.
+func (s *PostgresStore) returnTrade(req *models.Trade, status string, allowed func(*models.Trade) bool) (*models.Trade, error) {
+	if req.ID == 0 {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	trade, err := lockTrade(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed(trade) {
+		return nil, fmt.Errorf("robloxId cannot close this trade")
+	}
+
+	closed, err := refundTrade(ctx, tx, trade, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return closed, nil
+}
+
+// refundTrade closes a locked trade with status, refunding the held currency
+// to the sender's wallet and mailing the offered items back.
+func refundTrade(ctx context.Context, tx pgx.Tx, trade *models.Trade, status string) (*models.Trade, error) {
+	if trade.Offered.Amount > 0 {
+		if _, err := adjustWallet(ctx, tx, escrowAccount, trade.Offered.PriceType, -trade.Offered.Amount, "ESCROW_RELEASE", 0, fmt.Sprintf("trade:%d:release", trade.ID)); err != nil {
+			return nil, err
+		}
+
+		if _, err := adjustWallet(ctx, tx, trade.SenderID, trade.Offered.PriceType, trade.Offered.Amount, "TRADE_REFUND", 0, fmt.Sprintf("trade:%d:refund", trade.ID)); err != nil {
+			return nil, err
+		}
+	}
+
+	message := "Your trade was not completed. Your items have been returned to your mailbox."
+	if err := deliverTradeItems(ctx, tx, fmt.Sprintf("trade:%d:return", trade.ID), trade.SenderID, trade.SenderName, message, trade.Offered.Items); err != nil {
+		return nil, err
+	}
+
+	return closeTrade(ctx, tx, trade.ID, status)
+}
+
+// expireTrades returns every trade left PENDING longer than tradeSeconds to
+// its sender.
+func (s *PostgresStore) expireTrades() {
+	query := `SELECT id FROM trades WHERE status = 'PENDING' AND created <= CURRENT_TIMESTAMP - make_interval(secs => $1)`
+
+	rows, err := s.db.Query(context.Background(), query, s.tradeSeconds())
+	if err != nil {
+		fmt.Println("Failed to expire trades:", err)
+		return
+	}
+
+	var expired []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			fmt.Println("Failed to expire trades:", err)
+			return
+		}
+
+		expired = append(expired, id)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		fmt.Println("Failed to expire trades:", err)
+		return
+	}
+
+	for _, id := range expired {
+		if err := s.expireTrade(id); err != nil {
+			fmt.Printf("Failed to expire trade %d: %v\n", id, err)
+		}
+	}
+}
+
+func (s *PostgresStore) expireTrade(id int64) error {
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	trade, err := lockTrade(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := refundTrade(ctx, tx, trade, "EXPIRED"); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func closeTrade(ctx context.Context, tx pgx.Tx, id int64, status string) (*models.Trade, error) {
+	query := `UPDATE trades SET status = $2, responded = CURRENT_TIMESTAMP WHERE id = $1 RETURNING ` + tradeColumns
+
+	trade := &models.Trade{}
+	if err := scanTrade(tx.QueryRow(ctx, query, id, status), trade); err != nil {
+		return nil, fmt.Errorf("Unable to update row: %w", err)
+	}
+
+	return trade, nil
+}
+
+// deliverTradeItems queues one mailbox delivery per item, each under its own
+// idempotency key. Trade currency never goes through the mailbox.
+func deliverTradeItems(ctx context.Context, tx pgx.Tx, key string, robloxId int64, robloxName string, message string, items []models.TradeItem) error {
+	for i, item := range items {
+		var itemData map[string]interface{}
+		if err := json.Unmarshal(item.ItemData, &itemData); err != nil {
+			return fmt.Errorf("unable to unmarshal itemData: %w", err)
+		}
+
+		mail, err := newMailbox(robloxId, robloxName, message, itemData)
+		if err != nil {
+			return err
+		}
+
+		if err := enqueueMailbox(ctx, tx, fmt.Sprintf("%s:item:%d", key, i), mail); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetTradeHistory returns the trades a player has sent or received, newest
+// first, optionally filtered by status.
+func (s *PostgresStore) GetTradeHistory(req *models.Trade) ([]*models.Trade, error) {
+	if req.RobloxID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	query := `
+	SELECT ` + tradeColumns + ` FROM trades
+	WHERE (senderId = $1 OR targetId = $1) AND ($2 = '' OR status = $2)
+	ORDER BY id DESC
+	LIMIT $3
+	`
+
+	rows, err := s.db.Query(context.Background(), query, req.RobloxID, req.Status, LIMIT)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	trades := make([]*models.Trade, 0)
+
+	for rows.Next() {
+		trade := &models.Trade{}
+		if err := scanTrade(rows, trade); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		trades = append(trades, trade)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return trades, nil
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kattah7/v3/models"
+)
+
+func TestExpireTradesRefundsSender(t *testing.T) {
+	s := newTestStore(t)
+
+	const sender, target = 10, 20
+
+	inTx(t, s, func(ctx context.Context, tx pgx.Tx) error {
+		_, err := adjustWallet(ctx, tx, sender, "Coins", 100, "DEPOSIT", 0, "")
+		return err
+	})
+
+	trade, err := s.ProposeTrade(&models.Trade{
+		RobloxID:   sender,
+		RobloxName: "sender",
+		TargetID:   target,
+		TargetName: "target",
+		Offered:    models.TradeBundle{PriceType: "Coins", Amount: 40},
+	})
+	if err != nil {
+		t.Fatalf("ProposeTrade: %v", err)
+	}
+
+	if got := walletBalance(t, s, sender, "Coins"); got != 60 {
+		t.Fatalf("sender balance while pending = %d, want 60", got)
+	}
+
+	inTx(t, s, func(ctx context.Context, tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `UPDATE trades SET created = CURRENT_TIMESTAMP - make_interval(secs => $2 + 1) WHERE id = $1`, trade.ID, s.tradeSeconds())
+		return err
+	})
+
+	s.expireTrades()
+
+	var status string
+	if err := s.db.QueryRow(context.Background(), `SELECT status FROM trades WHERE id = $1`, trade.ID).Scan(&status); err != nil {
+		t.Fatalf("select trade: %v", err)
+	}
+
+	if status != "EXPIRED" {
+		t.Fatalf("status = %s, want EXPIRED", status)
+	}
+
+	if got := walletBalance(t, s, sender, "Coins"); got != 100 {
+		t.Errorf("sender balance after expiry = %d, want 100", got)
+	}
+
+	if got := walletBalance(t, s, escrowAccount, "Coins"); got != 0 {
+		t.Errorf("escrow balance after expiry = %d, want 0", got)
+	}
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kattah7/v3/models"
+)
+
+// escrowAccount is the wallet that holds a buyer's payment until the seller
+// claims it.
+const escrowAccount = 0
+
+// houseAccount is the wallet that collects auction taxes and listing fees.
+const houseAccount = -1
+
+// Credits create the wallet on first use. Debits only update an existing
+// wallet: the balance check would reject the negative row an upsert proposes
+// before it ever reached the conflict.
+const (
+	walletCreditQuery = `
+	INSERT INTO wallets (robloxId, priceType, balance) VALUES ($1, $2, $3)
+	ON CONFLICT (robloxId, priceType) DO UPDATE SET balance = wallets.balance + EXCLUDED.balance
+	RETURNING balance
+	`
+	walletDebitQuery = `
+	UPDATE wallets SET balance = balance + $3
+	WHERE robloxId = $1 AND priceType = $2
+	RETURNING balance
+	`
+)
+
+// adjustWallet applies amount to a player's balance and appends the movement
+// to the ledger. It must run inside the transaction that caused the movement.
+func adjustWallet(ctx context.Context, tx pgx.Tx, robloxId int64, priceType string, amount int64, reason string, auctionId int64, reference string) (int64, error) {
+	query := walletCreditQuery
+	if amount < 0 {
+		query = walletDebitQuery
+	}
+
+	var balance int64
+	err := tx.QueryRow(ctx, query, robloxId, priceType, amount).Scan(&balance)
+
+	var pgErr *pgconn.PgError
+	if errors.Is(err, pgx.ErrNoRows) || errors.As(err, &pgErr) && pgErr.Code == "23514" {
+		return 0, fmt.Errorf("insufficient %s balance", priceType)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("Unable to update wallet: %w", err)
+	}
+
+	ledgerQuery := `
+	INSERT INTO wallet_ledger (robloxId, priceType, amount, balance, reason, auctionId, reference)
+	VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), NULLIF($7, ''))
+	`
+
+	if _, err := tx.Exec(ctx, ledgerQuery, robloxId, priceType, amount, balance, reason, auctionId, reference); err != nil {
+		return 0, fmt.Errorf("unable to insert ledger row: %w", err)
+	}
+
+	return balance, nil
+}
+
+func (s *PostgresStore) DepositWallet(item *models.AuctionAccount) (*models.WalletBalance, error) {
+	if err := s.checkPriceType(item.PriceType); err != nil {
+		return nil, err
+	}
+
+	return s.moveWallet(item, item.Amount, "DEPOSIT")
+}
+
+func (s *PostgresStore) WithdrawWallet(item *models.AuctionAccount) (*models.WalletBalance, error) {
+	if err := s.checkKnownPriceType(item.PriceType); err != nil {
+		return nil, err
+	}
+
+	return s.moveWallet(item, -item.Amount, "WITHDRAW")
+}
+
+// moveWallet applies a deposit or withdrawal requested by a game server. A
+// reference that was already applied returns the current balance instead of
+// moving the currency twice.
+func (s *PostgresStore) moveWallet(item *models.AuctionAccount, amount int64, reason string) (*models.WalletBalance, error) {
+	if item.ID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	if item.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if item.Reference != "" {
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM wallet_ledger WHERE reference = $1)`, item.Reference).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("Unable to query row: %w", err)
+		}
+
+		if exists {
+			return getWalletBalance(ctx, tx, item.ID, item.PriceType)
+		}
+	}
+
+	balance, err := adjustWallet(ctx, tx, item.ID, item.PriceType, amount, reason, 0, item.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to commit transaction: %w", err)
+	}
+
+	return &models.WalletBalance{
+		RobloxID:  item.ID,
+		PriceType: item.PriceType,
+		Balance:   balance,
+	}, nil
+}
+
+func getWalletBalance(ctx context.Context, tx pgx.Tx, robloxId int64, priceType string) (*models.WalletBalance, error) {
+	balance := &models.WalletBalance{RobloxID: robloxId, PriceType: priceType}
+
+	query := `SELECT balance FROM wallets WHERE robloxId = $1 AND priceType = $2`
+	err := tx.QueryRow(ctx, query, robloxId, priceType).Scan(&balance.Balance)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	return balance, nil
+}
+
+func (s *PostgresStore) GetWalletBalances(item *models.AuctionAccount) ([]*models.WalletBalance, error) {
+	if item.ID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	query := `SELECT robloxId, priceType, balance FROM wallets WHERE robloxId = $1 ORDER BY priceType`
+
+	rows, err := s.db.Query(context.Background(), query, item.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	balances := make([]*models.WalletBalance, 0)
+
+	for rows.Next() {
+		balance := &models.WalletBalance{}
+		if err := rows.Scan(&balance.RobloxID, &balance.PriceType, &balance.Balance); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		balances = append(balances, balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return balances, nil
+}
+
+func (s *PostgresStore) GetWalletLedger(item *models.AuctionAccount) ([]*models.WalletLedgerEntry, error) {
+	if item.ID == 0 {
+		return nil, fmt.Errorf("robloxId cannot be empty")
+	}
+
+	query := `
+	SELECT id, robloxId, priceType, amount, balance, reason, COALESCE(auctionId, 0), COALESCE(reference, ''), created
+	FROM wallet_ledger
+	WHERE robloxId = $1 AND ($2 = '' OR priceType = $2)
+	ORDER BY id DESC
+	LIMIT $3
+	`
+
+	rows, err := s.db.Query(context.Background(), query, item.ID, item.PriceType, LIMIT)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	defer rows.Close()
+
+	entries := make([]*models.WalletLedgerEntry, 0)
+
+	for rows.Next() {
+		entry := &models.WalletLedgerEntry{}
+		if err := rows.Scan(&entry.ID, &entry.RobloxID, &entry.PriceType, &entry.Amount, &entry.Balance, &entry.Reason, &entry.AuctionID, &entry.Reference, &entry.Created); err != nil {
+			return nil, fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	return entries, nil
+}
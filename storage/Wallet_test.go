@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestAdjustWalletCreditThenDebit(t *testing.T) {
+	s := newTestStore(t)
+
+	inTx(t, s, func(ctx context.Context, tx pgx.Tx) error {
+		balance, err := adjustWallet(ctx, tx, 1, "Coins", 100, "DEPOSIT", 0, "")
+		if err != nil {
+			return err
+		}
+		if balance != 100 {
+			return fmt.Errorf("balance after credit = %d, want 100", balance)
+		}
+
+		balance, err = adjustWallet(ctx, tx, 1, "Coins", -40, "WITHDRAW", 0, "")
+		if err != nil {
+			return err
+		}
+		if balance != 60 {
+			return fmt.Errorf("balance after debit = %d, want 60", balance)
+		}
+
+		return nil
+	})
+
+	tests := []struct {
+		name     string
+		robloxId int64
+		amount   int64
+	}{
+		{"overdraft", 1, -61},
+		{"missing wallet", 2, -1},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		tx, err := s.db.Begin(ctx)
+		if err != nil {
+			t.Fatalf("begin: %v", err)
+		}
+
+		_, err = adjustWallet(ctx, tx, tt.robloxId, "Coins", tt.amount, "WITHDRAW", 0, "")
+		tx.Rollback(ctx)
+
+		if err == nil || err.Error() != "insufficient Coins balance" {
+			t.Errorf("%s: err = %v, want insufficient Coins balance", tt.name, err)
+		}
+	}
+}
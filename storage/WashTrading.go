@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultWashTradingCron     = "@every 1h"
+	defaultWashWindowDays      = 30
+	defaultWashPairSales       = 3
+	defaultWashMedianDeviation = 200
+	defaultWashMinMarketSales  = 5
+)
+
+// washThresholds resolves the configured thresholds against the defaults.
+func (s *PostgresStore) washThresholds() (windowDays int64, pairSales int64, deviation int64, minSales int64) {
+	cfg := s.cfg.WashTrading
+
+	windowDays, pairSales, deviation, minSales = cfg.WindowDays, cfg.PairSales, cfg.MedianDeviationPercent, cfg.MinMarketSales
+	if windowDays <= 0 {
+		windowDays = defaultWashWindowDays
+	}
+	if pairSales <= 0 {
+		pairSales = defaultWashPairSales
+	}
+	if deviation <= 0 {
+		deviation = defaultWashMedianDeviation
+	}
+	if minSales <= 0 {
+		minSales = defaultWashMinMarketSales
+	}
+
+	return windowDays, pairSales, deviation, minSales
+}
+
+func (s *PostgresStore) washTradingCron() string {
+	if s.cfg.WashTrading.Cron != "" {
+		return s.cfg.WashTrading.Cron
+	}
+
+	return defaultWashTradingCron
+}
+
+// detectWashTrading scans recent sales for repeated buyer/seller pairs,
+// prices far from the item's median and items that travel in a circle back
+// to their seller, and raises a flag with evidence for each finding.
+func (s *PostgresStore) detectWashTrading() {
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("Failed to detect wash trading:", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	windowDays, pairSales, deviation, minSales := s.washThresholds()
+
+	checks := []func(context.Context, pgx.Tx) error{
+		func(ctx context.Context, tx pgx.Tx) error { return flagRepeatedPairs(ctx, tx, windowDays, pairSales) },
+		func(ctx context.Context, tx pgx.Tx) error {
+			return flagPriceOutliers(ctx, tx, windowDays, deviation, minSales)
+		},
+		func(ctx context.Context, tx pgx.Tx) error {
+			return flagCircularTrades(ctx, tx, windowDays, s.provenanceField())
+		},
+	}
+
+	for _, check := range checks {
+		if err := check(ctx, tx); err != nil {
+			fmt.Println("Failed to detect wash trading:", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		fmt.Println("Failed to detect wash trading:", err)
+	}
+}
+
+type washSale struct {
+	AuctionID int64  `json:"auctionId"`
+	SellerID  int64  `json:"sellerId"`
+	BuyerID   int64  `json:"buyerId"`
+	ItemType  string `json:"itemType"`
+	ItemKey   string `json:"itemKey"`
+	PriceType string `json:"priceType"`
+	Price     int64  `json:"price"`
+}
+
+// flagRepeatedPairs flags two accounts that have sold to each other at least
+// pairSales times, in either direction, within the window. Each pair has one
+// flag whose evidence is brought up to date on every run.
+func flagRepeatedPairs(ctx context.Context, tx pgx.Tx, windowDays int64, pairSales int64) error {
+	query := `
+	SELECT LEAST(sellerId, buyerId), GREATEST(sellerId, buyerId), COUNT(*), array_agg(auctionId ORDER BY sold), SUM(price)
+	FROM auction_sales
+	WHERE sold > CURRENT_TIMESTAMP - make_interval(days => $1)
+	GROUP BY 1, 2
+	HAVING COUNT(*) >= $2
+	`
+
+	rows, err := tx.Query(ctx, query, windowDays, pairSales)
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	type pair struct {
+		A, B     int64
+		Sales    int64
+		Auctions []int64
+		Total    int64
+	}
+
+	var pairs []pair
+	for rows.Next() {
+		var p pair
+		if err := rows.Scan(&p.A, &p.B, &p.Sales, &p.Auctions, &p.Total); err != nil {
+			rows.Close()
+			return fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		pairs = append(pairs, p)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	for _, p := range pairs {
+		evidence := map[string]interface{}{
+			"accounts":   []int64{p.A, p.B},
+			"sales":      p.Sales,
+			"auctionIds": p.Auctions,
+			"totalPrice": p.Total,
+			"windowDays": windowDays,
+			"threshold":  pairSales,
+		}
+
+		fingerprint := fmt.Sprintf("REPEATED_PAIR:%d:%d", p.A, p.B)
+		if err := refreshFlag(ctx, tx, fingerprint, "REPEATED_PAIR", "", 0, p.A, evidence); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flagPriceOutliers flags sales priced deviation percent above, or the
+// matching fraction below, the item's median over the window. Items with
+// fewer than minSales sales have no trusted median and are skipped.
+func flagPriceOutliers(ctx context.Context, tx pgx.Tx, windowDays int64, deviation int64, minSales int64) error {
+	query := `
+	WITH medians AS (
+		SELECT itemType, itemKey, priceType, percentile_cont(0.5) WITHIN GROUP (ORDER BY price) AS median, COUNT(*) AS sales
+		FROM auction_sales
+		WHERE sold > CURRENT_TIMESTAMP - make_interval(days => $1)
+		GROUP BY itemType, itemKey, priceType
+		HAVING COUNT(*) >= $3
+	)
+	SELECT s.auctionId, s.sellerId, s.buyerId, s.itemType, s.itemKey, s.priceType, s.price, m.median::BIGINT, m.sales
+	FROM auction_sales s
+	JOIN medians m ON m.itemType = s.itemType AND m.itemKey = s.itemKey AND m.priceType = s.priceType
+	WHERE s.sold > CURRENT_TIMESTAMP - make_interval(days => $1) AND m.median > 0
+		AND (s.price * 100 >= m.median * (100 + $2) OR s.price * (100 + $2) <= m.median * 100)
+	`
+
+	rows, err := tx.Query(ctx, query, windowDays, deviation, minSales)
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	type outlier struct {
+		washSale
+		Median int64 `json:"median"`
+		Sales  int64 `json:"marketSales"`
+	}
+
+	var outliers []outlier
+	for rows.Next() {
+		var o outlier
+		if err := rows.Scan(&o.AuctionID, &o.SellerID, &o.BuyerID, &o.ItemType, &o.ItemKey, &o.PriceType, &o.Price, &o.Median, &o.Sales); err != nil {
+			rows.Close()
+			return fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		outliers = append(outliers, o)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	for _, o := range outliers {
+		evidence := map[string]interface{}{
+			"sale":             o,
+			"deviationPercent": deviation,
+			"windowDays":       windowDays,
+		}
+
+		if err := raiseFlag(ctx, tx, fmt.Sprintf("PRICE_OUTLIER:%d", o.AuctionID), "PRICE_OUTLIER", "", o.AuctionID, o.SellerID, evidence); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flagCircularTrades flags the same item passing A -> B -> A or
+// A -> B -> C -> A through consecutive sales within the window. Items are
+// matched on their provenance UUID when they have one, so two copies of a
+// common item sharing a market key don't look like one item going round.
+func flagCircularTrades(ctx context.Context, tx pgx.Tx, windowDays int64, provenanceField string) error {
+	query := `
+	WITH recent AS (
+		SELECT s.auctionId, s.sellerId, s.buyerId, s.sold, COALESCE(a.itemData->>$2, '') AS itemUuid,
+			COALESCE(NULLIF(a.itemData->>$2, ''), s.itemType || ':' || s.itemKey) AS itemId
+		FROM auction_sales s
+		LEFT JOIN auctions a ON a.id = s.auctionId
+		WHERE s.sold > CURRENT_TIMESTAMP - make_interval(days => $1)
+	)
+	SELECT ARRAY[a.auctionId, b.auctionId], a.itemUuid
+	FROM recent a
+	JOIN recent b ON b.itemId = a.itemId AND b.sellerId = a.buyerId AND b.buyerId = a.sellerId AND b.sold > a.sold
+	UNION ALL
+	SELECT ARRAY[a.auctionId, b.auctionId, c.auctionId], a.itemUuid
+	FROM recent a
+	JOIN recent b ON b.itemId = a.itemId AND b.sellerId = a.buyerId AND b.sold > a.sold
+	JOIN recent c ON c.itemId = a.itemId AND c.sellerId = b.buyerId AND c.buyerId = a.sellerId AND c.sold > b.sold
+	WHERE b.buyerId <> a.sellerId
+	`
+
+	rows, err := tx.Query(ctx, query, windowDays, provenanceField)
+	if err != nil {
+		return fmt.Errorf("Unable to query row: %w", err)
+	}
+
+	var cycles [][]int64
+	var itemUuids []string
+	for rows.Next() {
+		var cycle []int64
+		var itemUuid string
+		if err := rows.Scan(&cycle, &itemUuid); err != nil {
+			rows.Close()
+			return fmt.Errorf("Unable to scan row: %w", err)
+		}
+
+		cycles = append(cycles, cycle)
+		itemUuids = append(itemUuids, itemUuid)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Error iterating rows: %w", err)
+	}
+
+	saleQuery := `SELECT auctionId, sellerId, buyerId, itemType, itemKey, priceType, price FROM auction_sales WHERE auctionId = $1`
+
+	for i, cycle := range cycles {
+		sales := make([]washSale, 0, len(cycle))
+		for _, auctionId := range cycle {
+			var sale washSale
+			if err := tx.QueryRow(ctx, saleQuery, auctionId).Scan(&sale.AuctionID, &sale.SellerID, &sale.BuyerID, &sale.ItemType, &sale.ItemKey, &sale.PriceType, &sale.Price); err != nil {
+				return fmt.Errorf("Unable to query row: %w", err)
+			}
+
+			sales = append(sales, sale)
+		}
+
+		ids := make([]string, len(cycle))
+		sorted := append([]int64(nil), cycle...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		for i, id := range sorted {
+			ids[i] = fmt.Sprint(id)
+		}
+
+		evidence := map[string]interface{}{"sales": sales, "windowDays": windowDays}
+		fingerprint := "CIRCULAR_TRADE:" + strings.Join(ids, ":")
+		if err := raiseFlag(ctx, tx, fingerprint, "CIRCULAR_TRADE", itemUuids[i], cycle[0], sales[0].SellerID, evidence); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,13 +1,9 @@
 package storage
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-
-	"io"
-	"net/http"
 	"sync"
 	"time"
 
@@ -33,13 +29,70 @@ type Storage interface {
 	InsertAccounts(*models.Account) error
 
 	ListAuction(*models.AuctionAccount) error
+	ListAuctionBulk(*models.AuctionAccount) ([]*models.AuctionBulkResult, error)
+	UnlistAuctionBulk(*models.AuctionAccount) ([]*models.AuctionBulkResult, error)
 	RemoveAuction(*models.AuctionAccount) error
-	GetAuctions() ([]*models.AuctionAccount, error)
-	PurchaseAuction(*models.AuctionAccount) error
-	GetAuctionClaims(*models.AuctionAccount) ([]*models.AuctionAccount, error)
-	AuctionClaim(*models.AuctionAccount) error
+	GetAuctions(*models.AuctionAccount) (*models.AuctionPage, error)
+	PurchaseAuction(*models.AuctionAccount) (*models.AuctionAccount, error)
+	GetAuctionClaims(*models.AuctionAccount) (*models.AuctionClaims, error)
+	AuctionClaim(*models.AuctionAccount) (*models.AuctionAccount, error)
 	AuctionUnlist(*models.AuctionAccount) error
+	EditAuction(*models.AuctionAccount) (*models.AuctionAccount, error)
 	GetAuctionListing(*models.AuctionAccount) ([]*models.AuctionAccount, error)
+	AuctionExpireList(*models.AuctionAccount) ([]*models.AuctionAccount, error)
+	PlaceBid(*models.AuctionAccount) (*models.AuctionBid, error)
+	ReserveAuction(*models.AuctionAccount) (*models.AuctionAccount, error)
+	ConfirmAuction(*models.AuctionAccount) (*models.AuctionAccount, error)
+	ReleaseAuction(*models.AuctionAccount) error
+	MakeOffer(*models.AuctionAccount) (*models.AuctionOffer, error)
+	AcceptOffer(*models.AuctionAccount) (*models.AuctionAccount, error)
+	RejectOffer(*models.AuctionAccount) (*models.AuctionOffer, error)
+	CounterOffer(*models.AuctionAccount) (*models.AuctionOffer, error)
+	CancelOffer(*models.AuctionAccount) (*models.AuctionOffer, error)
+	GetOffers(*models.AuctionAccount) ([]*models.AuctionOffer, error)
+	WatchAuction(*models.AuctionAccount) error
+	UnwatchAuction(*models.AuctionAccount) error
+	GetWatchlist(*models.AuctionAccount) ([]*models.AuctionAccount, error)
+	CreateAuctionAlert(*models.AuctionAccount) (*models.AuctionAlert, error)
+	DeleteAuctionAlert(*models.AuctionAccount) error
+	GetAuctionAlerts(*models.AuctionAccount) ([]*models.AuctionAlert, error)
+	GetMarketStats(*models.AuctionAccount) ([]*models.MarketStats, error)
+	GetAuctionEvents(*models.AuctionAccount) ([]*models.AuctionEvent, error)
+
+	DepositWallet(*models.AuctionAccount) (*models.WalletBalance, error)
+	WithdrawWallet(*models.AuctionAccount) (*models.WalletBalance, error)
+	GetWalletBalances(*models.AuctionAccount) ([]*models.WalletBalance, error)
+	GetWalletLedger(*models.AuctionAccount) ([]*models.WalletLedgerEntry, error)
+
+	GetPlayerTier(*models.PlayerTier) (*models.PlayerTier, error)
+	SetPlayerTier(*models.PlayerTier) (*models.PlayerTier, error)
+
+	ProposeTrade(*models.Trade) (*models.Trade, error)
+	AcceptTrade(*models.Trade) (*models.Trade, error)
+	DeclineTrade(*models.Trade) (*models.Trade, error)
+	CancelTrade(*models.Trade) (*models.Trade, error)
+	GetTradeHistory(*models.Trade) ([]*models.Trade, error)
+	GetFeeReport(*models.AuctionFeeReport) ([]*models.AuctionFeeDay, error)
+	GetEconomyReport(*models.EconomyReportRequest) ([]*models.EconomyDay, error)
+	GetRegistry() (*models.Registry, error)
+	SetItemType(*models.RegistryRequest) (*models.ItemTypeEntry, error)
+	SetPriceType(*models.RegistryRequest) (*models.PriceTypeEntry, error)
+	RemoveItemType(*models.RegistryRequest) error
+	RemovePriceType(*models.RegistryRequest) error
+	GetAuctionFlags(*models.FlagRequest) ([]*models.AuctionFlag, error)
+	ReviewAuctionFlag(*models.FlagRequest) (*models.AuctionFlag, error)
+	GetProvenance(*models.FlagRequest) ([]*models.ProvenanceEntry, error)
+	BanPlayer(*models.ModerationRequest) (*models.AuctionBan, error)
+	UnbanPlayer(*models.ModerationRequest) (*models.AuctionBan, error)
+	GetBans(*models.ModerationRequest) ([]*models.AuctionBan, error)
+	HideAuction(*models.ModerationRequest) error
+	UnhideAuction(*models.ModerationRequest) error
+	ModerateRemoveAuction(*models.ModerationRequest) error
+	ModerateDeleteAuction(*models.ModerationRequest) error
+	RestoreAuction(*models.ModerationRequest) (*models.AuctionAccount, error)
+	GetModerationActions(*models.ModerationRequest) ([]*models.ModerationAction, error)
+	GetMailboxOutbox(*models.MailboxOutboxRequest) ([]*models.MailboxOutbox, error)
+	ReplayMailboxOutbox(*models.MailboxOutboxRequest) (*models.MailboxOutbox, error)
 
 	InsertPetsExistance(*models.PetsExistance) error
 	GetPetsExistance() ([]*models.GetPetsExistance, error)
@@ -56,9 +109,11 @@ type Storage interface {
 }
 
 type PostgresStore struct {
-	cfg *models.Config
-	db  *pgxpool.Pool
-	rdb *redis.Client
+	cfg      *models.Config
+	db       *pgxpool.Pool
+	rdb      *redis.Client
+	mailbox  MailboxSender
+	registry *typeRegistry
 }
 
 var (
@@ -74,16 +129,24 @@ func NewPostgresStore(ctx context.Context, cfg *models.Config, rdb *redis.Client
 	var err error
 
 	pgOnce.Do(func() {
-		db, err := pgxpool.New(ctx, cfg.DBConnString)
-		if err != nil {
-			err = fmt.Errorf("unable to connect to database: %v", err)
+		db, dbErr := pgxpool.New(ctx, cfg.DBConnString)
+		if dbErr != nil {
+			err = fmt.Errorf("unable to connect to database: %v", dbErr)
+			return
+		}
+
+		mailbox, mailboxErr := NewMailboxSender(cfg)
+		if mailboxErr != nil {
+			err = mailboxErr
 			return
 		}
 
 		pgInstance = &PostgresStore{
-			db:  db,
-			cfg: cfg,
-			rdb: rdb,
+			db:       db,
+			cfg:      cfg,
+			rdb:      rdb,
+			mailbox:  mailbox,
+			registry: &typeRegistry{},
 		}
 	})
 
@@ -94,11 +157,19 @@ func NewPostgresStore(ctx context.Context, cfg *models.Config, rdb *redis.Client
 	return pgInstance, nil
 }
 
+// SetMailboxSender replaces where outbox deliveries are sent, e.g. with a
+// MemoryMailboxSender in tests.
+func (s *PostgresStore) SetMailboxSender(sender MailboxSender) {
+	s.mailbox = sender
+}
+
 func (s *PostgresStore) Init() error {
 	return s.CreateTables()
 }
 
-func (s *PostgresStore) CreateTables() error {
+// createSchema creates and migrates every table and seeds the registry. It
+// is safe to run on every start.
+func (s *PostgresStore) createSchema() error {
 	queries := []string{
 		`CREATE TABLE IF NOT EXISTS players (
 			id SERIAL PRIMARY KEY,
@@ -123,6 +194,255 @@ func (s *PostgresStore) CreateTables() error {
 			listed TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			status VARCHAR(255) NOT NULL DEFAULT 'OPEN'
 		)`,
+		`ALTER TABLE auctions
+			ADD COLUMN IF NOT EXISTS highestBid BIGINT NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS highestBidderId BIGINT NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS highestBidderName VARCHAR(255) NOT NULL DEFAULT ''`,
+		`ALTER TABLE auctions
+			ADD COLUMN IF NOT EXISTS buyerId BIGINT NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS buyerName VARCHAR(255) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS purchased TIMESTAMP,
+			ADD COLUMN IF NOT EXISTS soldPrice BIGINT NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS itemClaimed BOOLEAN NOT NULL DEFAULT false,
+			ADD COLUMN IF NOT EXISTS proceedsClaimed BOOLEAN NOT NULL DEFAULT false`,
+		// Purchases made before buyers were recorded were delivered by the game server.
+		`UPDATE auctions SET itemClaimed = true, soldPrice = CASE WHEN highestBidderId <> 0 THEN highestBid ELSE startPrice END
+			WHERE status = 'PURCHASED' AND buyerId = 0 AND NOT itemClaimed`,
+		`CREATE TABLE IF NOT EXISTS auction_bids (
+			id SERIAL PRIMARY KEY,
+			auctionId INTEGER NOT NULL,
+			robloxId BIGINT NOT NULL,
+			robloxName VARCHAR(255) NOT NULL,
+			amount BIGINT NOT NULL,
+			priceType VARCHAR(255) NOT NULL,
+			status VARCHAR(255) NOT NULL DEFAULT 'ACTIVE',
+			placed TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_bids_auctionid ON auction_bids (auctionId)`,
+		`ALTER TABLE auction_bids ADD COLUMN IF NOT EXISTS escrowed BOOLEAN NOT NULL DEFAULT false`,
+		`ALTER TABLE auctions ADD COLUMN IF NOT EXISTS escrowed BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE auctions ADD COLUMN IF NOT EXISTS tax BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE auctions ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT false`,
+		`ALTER TABLE auctions ADD COLUMN IF NOT EXISTS mode VARCHAR(255) NOT NULL DEFAULT 'FIXED'`,
+		`ALTER TABLE auctions ADD COLUMN IF NOT EXISTS floorPrice BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE auctions ADD COLUMN IF NOT EXISTS priceStep BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE auctions ADD COLUMN IF NOT EXISTS stepSeconds BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE auctions ADD COLUMN IF NOT EXISTS reservePrice BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE auctions ADD COLUMN IF NOT EXISTS previousStatus VARCHAR(255) NOT NULL DEFAULT ''`,
+		`ALTER TABLE auctions ADD COLUMN IF NOT EXISTS deletedAt TIMESTAMP`,
+		`ALTER TABLE auctions
+			ADD COLUMN IF NOT EXISTS expiresAt TIMESTAMP,
+			ADD COLUMN IF NOT EXISTS expired TIMESTAMP,
+			ADD COLUMN IF NOT EXISTS expiredReason VARCHAR(255) NOT NULL DEFAULT ''`,
+		`ALTER TABLE auctions
+			ADD COLUMN IF NOT EXISTS reservedBy BIGINT NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS reservedUntil TIMESTAMP`,
+		`ALTER TABLE auctions
+			ADD COLUMN IF NOT EXISTS reserveCooldownBy BIGINT NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS reserveCooldownUntil TIMESTAMP`,
+		`CREATE INDEX IF NOT EXISTS idx_auctions_status ON auctions (status, itemType, priceType)`,
+		`CREATE INDEX IF NOT EXISTS idx_auctions_itemdata ON auctions USING GIN (itemData jsonb_path_ops)`,
+		`CREATE TABLE IF NOT EXISTS wallets (
+			robloxId BIGINT NOT NULL,
+			priceType VARCHAR(255) NOT NULL,
+			balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0),
+			PRIMARY KEY (robloxId, priceType)
+		)`,
+		`CREATE TABLE IF NOT EXISTS wallet_ledger (
+			id BIGSERIAL PRIMARY KEY,
+			robloxId BIGINT NOT NULL,
+			priceType VARCHAR(255) NOT NULL,
+			amount BIGINT NOT NULL,
+			balance BIGINT NOT NULL,
+			reason VARCHAR(255) NOT NULL,
+			auctionId INTEGER,
+			reference VARCHAR(255),
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_wallet_ledger_robloxid ON wallet_ledger (robloxId, priceType)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_ledger_reference ON wallet_ledger (reference)`,
+		// The ledger is append-only; balances are only ever changed alongside a new entry.
+		`CREATE OR REPLACE RULE wallet_ledger_no_update AS ON UPDATE TO wallet_ledger DO INSTEAD NOTHING`,
+		`CREATE OR REPLACE RULE wallet_ledger_no_delete AS ON DELETE TO wallet_ledger DO INSTEAD NOTHING`,
+		`CREATE TABLE IF NOT EXISTS auction_offers (
+			id SERIAL PRIMARY KEY,
+			auctionId INTEGER NOT NULL,
+			sellerId BIGINT NOT NULL,
+			robloxId BIGINT NOT NULL,
+			robloxName VARCHAR(255) NOT NULL,
+			amount BIGINT NOT NULL,
+			counterAmount BIGINT NOT NULL DEFAULT 0,
+			priceType VARCHAR(255) NOT NULL,
+			status VARCHAR(255) NOT NULL DEFAULT 'PENDING',
+			expiresAt TIMESTAMP NOT NULL,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_offers_auctionid ON auction_offers (auctionId, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_offers_robloxid ON auction_offers (robloxId)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_offers_sellerid ON auction_offers (sellerId)`,
+		`CREATE TABLE IF NOT EXISTS trades (
+			id SERIAL PRIMARY KEY,
+			senderId BIGINT NOT NULL,
+			senderName VARCHAR(255) NOT NULL,
+			targetId BIGINT NOT NULL,
+			targetName VARCHAR(255) NOT NULL,
+			offered JSONB NOT NULL,
+			requested JSONB NOT NULL,
+			status VARCHAR(255) NOT NULL DEFAULT 'PENDING',
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			responded TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_trades_senderid ON trades (senderId)`,
+		`CREATE INDEX IF NOT EXISTS idx_trades_targetid ON trades (targetId)`,
+		`CREATE TABLE IF NOT EXISTS auction_sales (
+			id SERIAL PRIMARY KEY,
+			auctionId INTEGER NOT NULL UNIQUE,
+			itemType VARCHAR(255) NOT NULL,
+			itemKey VARCHAR(255) NOT NULL,
+			priceType VARCHAR(255) NOT NULL,
+			price BIGINT NOT NULL,
+			sellerId BIGINT NOT NULL,
+			buyerId BIGINT NOT NULL,
+			sold TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_sales_item ON auction_sales (itemType, itemKey, priceType, sold)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_sales_sold ON auction_sales (sold)`,
+		`CREATE TABLE IF NOT EXISTS auction_fees (
+			id BIGSERIAL PRIMARY KEY,
+			auctionId INTEGER NOT NULL,
+			robloxId BIGINT NOT NULL,
+			kind VARCHAR(255) NOT NULL,
+			priceType VARCHAR(255) NOT NULL,
+			amount BIGINT NOT NULL,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_fees_created ON auction_fees (created)`,
+		`CREATE TABLE IF NOT EXISTS item_types (
+			name VARCHAR(255) PRIMARY KEY,
+			schema JSONB NOT NULL DEFAULT '{"type": "object"}',
+			active BOOLEAN NOT NULL DEFAULT true,
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS price_types (
+			name VARCHAR(255) PRIMARY KEY,
+			active BOOLEAN NOT NULL DEFAULT true,
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS item_provenance (
+			id BIGSERIAL PRIMARY KEY,
+			itemUuid TEXT NOT NULL,
+			auctionId INTEGER NOT NULL,
+			event VARCHAR(255) NOT NULL,
+			ownerId BIGINT NOT NULL,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// itemUuid comes from seller-supplied itemData and can be any length, so
+		// it is TEXT with a hash index rather than a size-limited btree.
+		`ALTER TABLE item_provenance ALTER COLUMN itemUuid TYPE TEXT`,
+		`DROP INDEX IF EXISTS idx_item_provenance_uuid`,
+		`CREATE INDEX IF NOT EXISTS idx_item_provenance_uuid_hash ON item_provenance USING hash (itemUuid)`,
+		`CREATE TABLE IF NOT EXISTS auction_flags (
+			id SERIAL PRIMARY KEY,
+			fingerprint VARCHAR(255) NOT NULL UNIQUE,
+			kind VARCHAR(255) NOT NULL,
+			itemUuid TEXT,
+			auctionId INTEGER,
+			robloxId BIGINT,
+			evidence JSONB NOT NULL DEFAULT '{}',
+			status VARCHAR(255) NOT NULL DEFAULT 'OPEN',
+			note TEXT NOT NULL DEFAULT '',
+			reviewer VARCHAR(255) NOT NULL DEFAULT '',
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			reviewed TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_flags_status ON auction_flags (status, kind)`,
+		`ALTER TABLE auction_flags ALTER COLUMN itemUuid TYPE TEXT`,
+		`CREATE TABLE IF NOT EXISTS auction_bans (
+			id SERIAL PRIMARY KEY,
+			robloxId BIGINT NOT NULL,
+			scope VARCHAR(255) NOT NULL,
+			reason TEXT NOT NULL,
+			moderator VARCHAR(255) NOT NULL,
+			until TIMESTAMP,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			lifted TIMESTAMP,
+			liftedBy VARCHAR(255) NOT NULL DEFAULT '',
+			liftReason TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_bans_robloxid ON auction_bans (robloxId)`,
+		`CREATE TABLE IF NOT EXISTS auction_moderation (
+			id SERIAL PRIMARY KEY,
+			action VARCHAR(255) NOT NULL,
+			robloxId BIGINT,
+			auctionId INTEGER,
+			reason TEXT NOT NULL,
+			moderator VARCHAR(255) NOT NULL,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_moderation_robloxid ON auction_moderation (robloxId)`,
+		`CREATE TABLE IF NOT EXISTS auction_price_history (
+			id SERIAL PRIMARY KEY,
+			auctionId INTEGER NOT NULL,
+			oldPrice BIGINT NOT NULL,
+			newPrice BIGINT NOT NULL,
+			oldPriceType VARCHAR(255) NOT NULL,
+			newPriceType VARCHAR(255) NOT NULL,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_price_history_auctionid ON auction_price_history (auctionId)`,
+		`CREATE TABLE IF NOT EXISTS auction_watchlist (
+			robloxId BIGINT NOT NULL,
+			auctionId INTEGER NOT NULL,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (robloxId, auctionId)
+		)`,
+		`CREATE TABLE IF NOT EXISTS auction_alerts (
+			id SERIAL PRIMARY KEY,
+			robloxId BIGINT NOT NULL,
+			robloxName VARCHAR(255) NOT NULL,
+			itemType VARCHAR(255) NOT NULL,
+			itemMatch JSONB NOT NULL DEFAULT '{}',
+			priceType VARCHAR(255) NOT NULL,
+			maxPrice BIGINT NOT NULL,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_alerts_match ON auction_alerts (itemType, priceType, maxPrice)`,
+		`CREATE TABLE IF NOT EXISTS player_tiers (
+			robloxId BIGINT PRIMARY KEY,
+			tier VARCHAR(255) NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS mailbox_outbox (
+			id BIGSERIAL PRIMARY KEY,
+			idempotencyKey VARCHAR(255) NOT NULL UNIQUE,
+			robloxId BIGINT NOT NULL,
+			robloxName VARCHAR(255) NOT NULL,
+			payload JSONB NOT NULL,
+			status VARCHAR(255) NOT NULL DEFAULT 'PENDING',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			nextAttempt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			lastError TEXT NOT NULL DEFAULT '',
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			sent TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_mailbox_outbox_status ON mailbox_outbox (status, nextAttempt)`,
+		`CREATE TABLE IF NOT EXISTS auction_events (
+			id BIGSERIAL PRIMARY KEY,
+			auctionId INTEGER NOT NULL,
+			event VARCHAR(255) NOT NULL,
+			sellerId BIGINT NOT NULL,
+			actorId BIGINT NOT NULL DEFAULT 0,
+			actorName VARCHAR(255) NOT NULL DEFAULT '',
+			itemType VARCHAR(255) NOT NULL,
+			itemData JSONB NOT NULL,
+			priceType VARCHAR(255) NOT NULL,
+			price BIGINT NOT NULL,
+			detail VARCHAR(255) NOT NULL DEFAULT '',
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_events_auctionid ON auction_events (auctionId)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_events_sellerid ON auction_events (sellerId)`,
+		`CREATE INDEX IF NOT EXISTS idx_auction_events_actorid ON auction_events (actorId)`,
 		`CREATE TABLE IF NOT EXISTS pets_exist (
 			id SERIAL PRIMARY KEY,
 			robloxId BIGINT NOT NULL,
@@ -155,114 +475,66 @@ func (s *PostgresStore) CreateTables() error {
 		}
 	}
 
-	c := cron.New()
-	c.AddFunc(s.cfg.Cron, func() {
-		currentTime := time.Now().Local()
-		cutoffDuration := time.Duration(s.cfg.CutOffTime) * time.Second
-		cutoffTime := currentTime.Add(cutoffDuration)
-
-		query := `SELECT robloxId, robloxName, itemData FROM auctions WHERE listed < $1 AND status = 'OPEN'`
-
-		rows, err := s.db.Query(context.Background(), query, cutoffTime)
-		if err != nil {
-			fmt.Printf("unable to query database: %v", err)
-			return
-		}
-
-		defer rows.Close()
+	// Listings created before per-listing durations keep the global cutoff.
+	backfillQuery := `UPDATE auctions SET expiresAt = listed + make_interval(secs => $1) WHERE expiresAt IS NULL`
+	if _, err := s.db.Exec(context.Background(), backfillQuery, s.legacyDuration()); err != nil {
+		return err
+	}
 
-		for rows.Next() {
-			var robloxId int64
-			var robloxName string
-			var itemData map[string]interface{}
+	if err := s.seedRegistry(); err != nil {
+		return err
+	}
 
-			err := rows.Scan(&robloxId, &robloxName, &itemData)
-			if err != nil {
-				fmt.Printf("unable to query database: %v", err)
-				return
-			}
+	if err := s.backfillSales(); err != nil {
+		return err
+	}
 
-			itemData["timestamp"] = time.Now().Unix()
-			itemData["message"] = "This item has expired and has been returned to your mailbox."
-			itemData["senderId"] = 1
-			itemData["senderName"] = "PlayCrate"
-			itemData["displayName"] = "PlayCrate"
-			itemData["targetId"] = robloxId
-
-			updatedItemData, err := json.Marshal(itemData)
-			if err != nil {
-				fmt.Printf("unable to marshal itemData: %v", err)
-				return
-			}
+	return nil
+}
 
-			body := models.MailboxExpire{
-				RobloxName: robloxName,
-				RobloxId:   robloxId,
-				Type:       "ADD",
-				Payload:    json.RawMessage(fmt.Sprintf("[%s]", updatedItemData)),
-			}
+// CreateTables creates the schema and starts the background jobs.
+func (s *PostgresStore) CreateTables() error {
+	if err := s.createSchema(); err != nil {
+		return err
+	}
 
-			jsonData, err := json.Marshal(body)
-			if err != nil {
-				fmt.Printf("Failed to marshal auction data: %v\n", err)
-				return
-			}
+	c := cron.New()
+	c.AddFunc(s.cfg.Cron, func() {
+		s.expireAuctions()
+	})
 
-			var baseURL string
-			if s.cfg.Prod {
-				baseURL = "https://roblox.kattah.me/mailbox"
-			} else {
-				baseURL = "https://playcrate-debug.kattah.me/mailbox"
-			}
+	c.AddFunc("@every 1m", func() {
+		s.releaseLapsedReservations()
+	})
 
-			req, err := http.NewRequest("POST", baseURL, bytes.NewBuffer(jsonData))
-			if err != nil {
-				fmt.Printf("Failed to create HTTP request: %v\n", err)
-				return
-			}
+	c.AddFunc("@every 1m", func() {
+		s.expireOffers()
+	})
 
-			req.Header.Set("authorization", s.cfg.V1Auth)
-			req.Header.Set("Content-Type", "application/json")
+	c.AddFunc("@every 1m", func() {
+		s.expireTrades()
+	})
 
-			client := &http.Client{}
-			resp, err := client.Do(req)
-			if err != nil {
-				fmt.Printf("Failed to send API request: %v\n", err)
-				return
-			}
-			defer resp.Body.Close()
+	c.AddFunc("@every 1h", func() {
+		s.deliverUnclaimedProceeds()
+	})
 
-			if resp.StatusCode != http.StatusOK {
-				fmt.Printf("API request failed with status code: %d\n", resp.StatusCode)
-				return
-			}
+	c.AddFunc("@every 1m", func() {
+		s.notifyDutchAlerts()
+	})
 
-			bodya, err := io.ReadAll(resp.Body)
-			if err != nil {
-				fmt.Printf("Failed to read API response body: %v\n", err)
-				return
-			}
+	c.AddFunc("@every 1m", func() {
+		if err := s.loadRegistry(); err != nil {
+			fmt.Println("Failed to reload registry:", err)
+		}
+	})
 
-			var apiResp models.ApiResponse
-			err = json.Unmarshal(bodya, &apiResp)
-			if err != nil {
-				fmt.Printf("Failed to unmarshal API response body: %v\n", err)
-				return
-			}
+	c.AddFunc(s.washTradingCron(), func() {
+		s.detectWashTrading()
+	})
 
-			if apiResp.Success == true {
-				deleteQuery := `DELETE FROM auctions WHERE listed < $1 AND status = 'OPEN'`
-				_, err = s.db.Exec(context.Background(), deleteQuery, cutoffTime)
-				if err != nil {
-					fmt.Println("Failed to delete rows from auctions:", err)
-					return
-				}
-
-				fmt.Println("Successfully sent expired auction to mailbox")
-			} else {
-				fmt.Println("Failed to send expired auction to mailbox")
-			}
-		}
+	c.AddFunc("@every 15s", func() {
+		s.deliverMailboxOutbox()
 	})
 
 	cacheDB := func() {
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kattah7/v3/models"
+)
+
+// newTestStore connects to the database named by TEST_DB_CONN_STRING and
+// creates the schema in a Postgres schema of its own, dropped when the test
+// ends. Tests that need a database are skipped when it is not set.
+func newTestStore(t *testing.T) *PostgresStore {
+	t.Helper()
+
+	connString := os.Getenv("TEST_DB_CONN_STRING")
+	if connString == "" {
+		t.Skip("TEST_DB_CONN_STRING is not set")
+	}
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+
+	conn, err := pgx.Connect(ctx, connString)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	if _, err := conn.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		conn.Close(ctx)
+		t.Fatalf("create schema: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE")
+		conn.Close(ctx)
+	})
+
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+
+	db, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("connect pool: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	s := &PostgresStore{
+		db:       db,
+		cfg:      &models.Config{},
+		mailbox:  &MemoryMailboxSender{},
+		registry: &typeRegistry{},
+	}
+
+	if err := s.createSchema(); err != nil {
+		t.Fatalf("createSchema: %v", err)
+	}
+
+	return s
+}
+
+// inTx runs fn in a transaction and commits it, failing the test on error.
+func inTx(t *testing.T, s *PostgresStore, fn func(ctx context.Context, tx pgx.Tx) error) {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+}